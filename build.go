@@ -1,36 +1,163 @@
 package epp
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/domainr/epp2/internal/xml"
 	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/std"
 )
 
+// Greeting returns a server <greeting> for cfg. The <svID> is cfg.ServerName,
+// and the <svcMenu> lists the versions, languages, objects, and extensions in
+// cfg, using defaults for any that are nil. If cfg.Objects is nil, the objects
+// announced are those in cfg.Schemas.
 func Greeting(cfg *Config) (epp.Body, error) {
-	return &epp.Greeting{}, nil // TODO
+	menu := &epp.ServiceMenu{
+		Versions:  copySlice(cfg.Versions),
+		Languages: copySlice(cfg.Languages),
+		Objects:   copySlice(cfg.Objects),
+	}
+	if menu.Versions == nil {
+		menu.Versions = copySlice(defaultVersions)
+	}
+	if menu.Languages == nil {
+		menu.Languages = copySlice(defaultLanguages)
+	}
+	if menu.Objects == nil {
+		menu.Objects = defaultObjects(cfg.Schemas)
+	}
+	if len(cfg.Extensions) > 0 {
+		menu.ServiceExtension = &epp.ServiceExtension{
+			Extensions: copySlice(cfg.Extensions),
+		}
+	}
+	dcp := cfg.DCP
+	if dcp == nil {
+		dcp = defaultDCP()
+	}
+	return &epp.Greeting{
+		ServerName:  cfg.ServerName,
+		ServerDate:  std.Time{Time: time.Now().UTC()}.Pointer(),
+		ServiceMenu: menu,
+		DCP:         dcp,
+	}, nil
+}
+
+// defaultDCP returns the data collection policy used if [Config].DCP is nil.
+func defaultDCP() *epp.DCP {
+	return &epp.DCP{
+		Access: epp.AccessAll,
+		Statements: []epp.Statement{{
+			Purpose: epp.Purpose{Admin: true, Provisioning: true},
+			Recipient: epp.Recipient{
+				Ours:   &epp.Ours{},
+				Public: true,
+			},
+		}},
+	}
 }
 
 func Command(cfg *Config, action epp.Action, extensions ...epp.Extension) (epp.Body, error) {
 	return &epp.Command{
 		Action:              action,
 		Extensions:          extensions,
-		ClientTransactionID: cfg.TransactionID(),
+		ClientTransactionID: cfg.transactionID(),
 	}, nil
 }
 
 func Login(cfg *Config, clientID, password string, newPassword *string) (epp.Body, error) {
-	return Command(cfg, &epp.Login{
+	login := &epp.Login{
 		ClientID:    clientID,
 		Password:    password,
 		NewPassword: newPassword,
 		Options: epp.Options{
 			Version: epp.Version,
 		},
-	})
+		Services: epp.Services{
+			Objects: cfg.Objects,
+		},
+	}
+	if len(cfg.Languages) > 0 {
+		login.Options.Lang = cfg.Languages[0]
+	}
+	exts := append(copySlice(cfg.Extensions), cfg.UnannouncedExtensions...)
+	if len(exts) > 0 {
+		login.Services.ServiceExtension = &epp.ServiceExtension{Extensions: exts}
+	}
+	return Command(cfg, login)
 }
 
 func Logout(cfg *Config) (epp.Body, error) {
 	return Command(cfg, &epp.Logout{})
 }
 
+// ErrorResponse returns a server <response> describing err, with a new server
+// transaction ID. The caller should set the client transaction ID, if any.
+//
+// If cfg.ErrorResult is not nil, it is consulted first. Otherwise, err is
+// mapped to a <result> as follows:
+//   - An [*epp.Result] is used as-is.
+//   - An [epp.ResultCode] error code is used with its default message.
+//   - A malformed or unexpected message results in 2001 (command syntax error).
+//   - A [DuplicateTransactionIDError] results in 2306 (parameter value policy
+//     error).
+//   - [ErrServerClosed] or [context.Canceled] results in 2500 (command failed;
+//     server closing connection). A [Server] session is closed after sending
+//     it.
+//   - Any other error, including [context.DeadlineExceeded], results in 2400
+//     (command failed).
 func ErrorResponse(cfg *Config, err error) epp.Body {
-	return nil // TODO
+	return errorResponse(cfg, err)
+}
+
+func errorResponse(cfg *Config, err error) *epp.Response {
+	return &epp.Response{
+		Results: []epp.Result{errorResult(cfg, err)},
+		TransactionID: epp.TransactionID{
+			Server: cfg.transactionID(),
+		},
+	}
+}
+
+func errorResult(cfg *Config, err error) epp.Result {
+	if cfg.ErrorResult != nil {
+		if r := cfg.ErrorResult(err); r != nil {
+			return withMessage(*r)
+		}
+	}
+	var r *epp.Result
+	if errors.As(err, &r) && r != nil {
+		return withMessage(*r)
+	}
+	var code epp.ResultCode
+	var dup DuplicateTransactionIDError
+	var syntaxErr *xml.SyntaxError
+	var unmarshalErr xml.UnmarshalError
+	switch {
+	case errors.As(err, &code) && code.IsError():
+	case errors.As(err, &dup):
+		return *elementResult(epp.ErrParameterPolicy, "clTRID", dup.TransactionID)
+	case errors.As(err, &syntaxErr),
+		errors.As(err, &unmarshalErr),
+		errors.Is(err, ErrUnexpectedMessage):
+		code = epp.ErrCommandSyntax
+	case errors.Is(err, ErrServerClosed),
+		errors.Is(err, context.Canceled):
+		code = epp.ErrCommandFailedClosing
+	default:
+		code = epp.ErrCommandFailed
+	}
+	return epp.Result{Code: code, Message: code.Message()}
+}
+
+// withMessage returns r with the default message for its code if r has no
+// message.
+func withMessage(r epp.Result) epp.Result {
+	if r.Message == (epp.Message{}) {
+		r.Message = r.Code.Message()
+	}
+	return r
 }
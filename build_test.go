@@ -0,0 +1,116 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/domainr/epp2/internal/xml"
+	"github.com/domainr/epp2/ns"
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+func TestGreeting(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want epp.ServiceMenu
+	}{
+		{
+			`defaults`,
+			Config{Schemas: []schema.Schema{epp.Schema, domain.Schema}},
+			epp.ServiceMenu{
+				Versions:  []string{"1.0"},
+				Languages: []string{"en"},
+				Objects:   []string{domain.NS},
+			},
+		},
+		{
+			`config`,
+			Config{
+				Languages:  []string{"en", "fr"},
+				Objects:    []string{ns.Domain, ns.Host},
+				Extensions: []string{ns.IDN},
+			},
+			epp.ServiceMenu{
+				Versions:         []string{"1.0"},
+				Languages:        []string{"en", "fr"},
+				Objects:          []string{ns.Domain, ns.Host},
+				ServiceExtension: &epp.ServiceExtension{Extensions: []string{ns.IDN}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.ServerName = "Test EPP Server"
+			body, err := Greeting(&tt.cfg)
+			if err != nil {
+				t.Fatalf("Greeting(): err == %v", err)
+			}
+			g := body.(*epp.Greeting)
+			if g.ServerName != tt.cfg.ServerName {
+				t.Errorf("Greeting(): ServerName == %q, expected %q", g.ServerName, tt.cfg.ServerName)
+			}
+			if g.ServerDate == nil || g.ServerDate.IsZero() {
+				t.Error("Greeting(): empty ServerDate")
+			}
+			if !reflect.DeepEqual(*g.ServiceMenu, tt.want) {
+				t.Errorf("Greeting():\nGot:  %+v\nWant: %+v", *g.ServiceMenu, tt.want)
+			}
+			if !reflect.DeepEqual(g.DCP, defaultDCP()) {
+				t.Errorf("Greeting(): DCP == %+v, expected default", g.DCP)
+			}
+		})
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	errCustom := errors.New("custom")
+	cfg := &Config{
+		TransactionID: func() string { return "sv-1" },
+		ErrorResult: func(err error) *epp.Result {
+			if errors.Is(err, errCustom) {
+				return &epp.Result{Code: epp.ErrDoesNotExist}
+			}
+			return nil
+		},
+	}
+	tests := []struct {
+		name string
+		err  error
+		want epp.ResultCode
+	}{
+		{`result`, &epp.Result{Code: epp.ErrAuthorization}, epp.ErrAuthorization},
+		{`result code`, epp.ErrBillingFailure, epp.ErrBillingFailure},
+		{`wrapped result code`, fmt.Errorf("wrapped: %w", epp.ErrExists), epp.ErrExists},
+		{`syntax error`, &xml.SyntaxError{Msg: "bad", Line: 1}, epp.ErrCommandSyntax},
+		{`unexpected message`, ErrUnexpectedMessage, epp.ErrCommandSyntax},
+		{`duplicate transaction ID`, DuplicateTransactionIDError{TransactionID: "abc"}, epp.ErrParameterPolicy},
+		{`server closed`, ErrServerClosed, epp.ErrCommandFailedClosing},
+		{`canceled`, context.Canceled, epp.ErrCommandFailedClosing},
+		{`deadline`, context.DeadlineExceeded, epp.ErrCommandFailed},
+		{`custom`, fmt.Errorf("wrapped: %w", errCustom), epp.ErrDoesNotExist},
+		{`unknown`, errors.New("unknown"), epp.ErrCommandFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, ok := ErrorResponse(cfg, tt.err).(*epp.Response)
+			if !ok {
+				t.Fatalf("ErrorResponse(): expected *epp.Response")
+			}
+			if len(res.Results) != 1 || res.Results[0].Code != tt.want {
+				t.Errorf("ErrorResponse(): results %+v, expected code %04d", res.Results, tt.want)
+			}
+			if msg := res.Results[0].Message; msg != tt.want.Message() {
+				t.Errorf("ErrorResponse(): message %+v, expected %+v", msg, tt.want.Message())
+			}
+			if res.TransactionID.Server != "sv-1" {
+				t.Errorf("ErrorResponse(): svTRID == %q, expected %q", res.TransactionID.Server, "sv-1")
+			}
+		})
+	}
+}
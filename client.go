@@ -3,23 +3,95 @@ package epp
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/domainr/epp2/internal/config"
 	"github.com/domainr/epp2/protocol"
+	"github.com/domainr/epp2/schema/domain"
 	"github.com/domainr/epp2/schema/epp"
 )
 
+// Client represents a high-level EPP client connection.
+// A Client is safe to use from multiple goroutines.
 type Client interface {
-	// Login(username, password, newPassword string) error
-	// Logout() error
+	// ExchangeEPP sends an EPP message and returns an EPP response.
+	// It blocks until a response is received, the Context is canceled, or
+	// the underlying connection is closed. If the pipeline is full (see
+	// [WithPipeline]), it waits for an in-flight command to complete.
+	ExchangeEPP(context.Context, epp.Body) (epp.Body, error)
+
+	// Hello sends an EPP <hello> to the server and returns the server’s
+	// <greeting>, which replaces the greeting cached by the Client.
+	Hello(context.Context) (*epp.Greeting, error)
+
+	// InFlight returns the number of commands sent to the server that are
+	// awaiting a response.
+	InFlight() int
+
+	// Login sends an EPP <login> command to the server, using the services
+	// negotiated from the server’s <greeting>. If newPassword is non-nil,
+	// the server will be asked to change the client’s password.
+	// A non-nil error is returned if the server does not respond with a
+	// successful (1000) result.
+	Login(ctx context.Context, clientID, password string, newPassword *string) error
+
+	// Logout sends an EPP <logout> command to the server and closes the
+	// underlying connection.
+	Logout(ctx context.Context) error
+
+	// CheckDomains sends an EPP <domain:check> command for names to the
+	// server, and returns the availability of each name reported by the
+	// server, keyed by domain name.
+	CheckDomains(ctx context.Context, names ...string) (map[string]DomainCheck, error)
+
+	// Shutdown gracefully closes the client. It stops accepting new
+	// commands, waits for in-flight commands to complete, sends a <logout>
+	// if the client is logged in, and then closes the underlying
+	// connection. If ctx is canceled first, the connection is closed and
+	// the Context error is returned.
+	//
+	// Commands submitted after Shutdown is called return
+	// [ErrClosedConnection].
+	Shutdown(ctx context.Context) error
+
+	// Close immediately closes the underlying connection. In-flight
+	// commands and commands submitted after Close is called return
+	// [ErrClosedConnection].
 	Close() error
 }
 
 type client struct {
-	conn     net.Conn
-	client   protocol.Client
-	greeting epp.Body
+	conn   net.Conn
+	client protocol.Client
+	cfg    *Config
+
+	// window limits the number of in-flight commands.
+	window chan struct{}
+
+	// timeout, if non-zero, limits the duration of each command.
+	timeout time.Duration
+
+	// lastActive is the time of the last command, in Unix nanoseconds.
+	lastActive atomic.Int64
+
+	// pending tracks commands accepted by the client.
+	pending sync.WaitGroup
+
+	closeOnce sync.Once
+	closeErr  error
+	done      chan struct{}
+
+	mu       sync.Mutex
+	greeting *epp.Greeting
+	loggedIn bool
+	closing  bool
+
+	// transactions contains the client transaction IDs of in-flight commands.
+	transactions map[string]struct{}
 }
 
 func Dial(network, addr string, opts ...Options) (Client, error) {
@@ -62,19 +134,428 @@ func connect(conn net.Conn, cfg config.Config) (Client, error) {
 		ctx = context.Background()
 	}
 
-	c, greeting, err := protocol.Connect(ctx, conn)
+	c, body, err := protocol.Connect(ctx, conn, cfg.Schemas...)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
 
-	return &client{
-		conn:     conn,
-		client:   c,
+	greeting, ok := body.(*epp.Greeting)
+	if !ok {
+		conn.Close()
+		return nil, ErrUnexpectedMessage
+	}
+
+	seq, err := newSeqSource("")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	cl := &client{
+		conn:   conn,
+		client: c,
+		cfg: &Config{
+			Versions:              cfg.Versions,
+			Objects:               cfg.Objects,
+			Extensions:            cfg.Extensions,
+			UnannouncedExtensions: cfg.UnannouncedExtensions,
+			Schemas:               cfg.Schemas,
+			TransactionID:         seq.ID,
+		},
+		window:   make(chan struct{}, max(cfg.Pipeline, 1)),
+		timeout:  cfg.Timeout,
+		done:     make(chan struct{}),
 		greeting: greeting,
-	}, nil
+	}
+	cl.lastActive.Store(time.Now().UnixNano())
+	if cfg.KeepAlive > 0 {
+		go cl.keepAlive(cfg.KeepAlive)
+	}
+	return cl, nil
+}
+
+func (c *client) Login(ctx context.Context, clientID, password string, newPassword *string) error {
+	cfg, err := c.negotiate()
+	if err != nil {
+		return err
+	}
+	return c.login(ctx, cfg, clientID, password, newPassword)
+}
+
+// negotiate returns a Config with the capabilities shared by the client and
+// the server’s most recent <greeting>.
+func (c *client) negotiate() (*Config, error) {
+	c.mu.Lock()
+	greeting := c.greeting
+	c.mu.Unlock()
+	return ConfigForGreeting(c.cfg, greeting)
+}
+
+// login sends a <login> command using the services negotiated in cfg.
+func (c *client) login(ctx context.Context, cfg *Config, clientID, password string, newPassword *string) error {
+	req, err := Login(cfg, clientID, password, newPassword)
+	if err != nil {
+		return err
+	}
+	_, err = c.command(ctx, req, epp.Success)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.loggedIn = true
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *client) Logout(ctx context.Context) error {
+	req, err := Logout(c.cfg)
+	if err != nil {
+		return err
+	}
+	_, err = c.command(ctx, req, epp.SuccessEnd)
+
+	c.mu.Lock()
+	c.loggedIn = false
+	c.mu.Unlock()
+
+	// The server will close the connection after a successful <logout>.
+	cerr := c.Close()
+	if err != nil {
+		return err
+	}
+	return cerr
+}
+
+func (c *client) CheckDomains(ctx context.Context, names ...string) (map[string]DomainCheck, error) {
+	return checkDomains(ctx, c, names)
+}
+
+func (c *client) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	c.closing = true
+	loggedIn := c.loggedIn
+	c.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.pending.Wait()
+		close(drained)
+	}()
+	select {
+	case <-ctx.Done():
+		c.Close()
+		return context.Cause(ctx)
+	case <-drained:
+	}
+
+	if loggedIn {
+		req, err := Logout(c.cfg)
+		if err == nil {
+			body, _, err2 := c.roundTrip(ctx, req)
+			_, err = checkResponse(body, err2, epp.SuccessEnd)
+		}
+		if err != nil {
+			c.Close()
+			return err
+		}
+	}
+	return c.Close()
 }
 
 func (c *client) Close() error {
-	// TODO: handle pending transactions
-	return c.conn.Close()
+	c.mu.Lock()
+	c.closing = true
+	c.loggedIn = false
+	c.mu.Unlock()
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.closeErr = c.conn.Close()
+	})
+	return c.closeErr
+}
+
+// isClosing returns true if the client is shutting down or closed.
+func (c *client) isClosing() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closing
+}
+
+// closed returns true if the client has been closed.
+func (c *client) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *client) Hello(ctx context.Context) (*epp.Greeting, error) {
+	body, err := c.exchange(ctx, &epp.Hello{})
+	if err != nil {
+		return nil, err
+	}
+	greeting, ok := body.(*epp.Greeting)
+	if !ok {
+		return nil, ErrUnexpectedMessage
+	}
+	c.mu.Lock()
+	c.greeting = greeting
+	c.mu.Unlock()
+	return greeting, nil
+}
+
+// keepAlive sends a <hello> to the server after the connection has been idle
+// for duration d, until the client is closed. If the connection has failed,
+// keepAlive closes the client. Other errors are ignored, as they will be
+// surfaced to the next caller of the client.
+func (c *client) keepAlive(d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+		}
+		idle := time.Since(time.Unix(0, c.lastActive.Load()))
+		switch {
+		case c.InFlight() > 0:
+			t.Reset(d)
+		case idle < d:
+			t.Reset(d - idle)
+		default:
+			_, err := c.Hello(context.Background())
+			var netErr net.Error
+			if err != nil && isConnError(err) && !(errors.As(err, &netErr) && netErr.Timeout()) {
+				c.Close()
+				return
+			}
+			t.Reset(d)
+		}
+	}
+}
+
+func (c *client) ExchangeEPP(ctx context.Context, req epp.Body) (epp.Body, error) {
+	return c.exchange(ctx, req)
+}
+
+func (c *client) InFlight() int {
+	return len(c.window)
+}
+
+// exchange sends req to the server and returns the response.
+// It returns [ErrClosedConnection] if the client is shutting down or closed.
+func (c *client) exchange(ctx context.Context, req epp.Body) (epp.Body, error) {
+	body, _, err := c.transact(ctx, req)
+	return body, err
+}
+
+// transact is like exchange, but also reports whether req was sent to the
+// server. If req was not sent, it is safe to resubmit.
+func (c *client) transact(ctx context.Context, req epp.Body) (epp.Body, bool, error) {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return nil, false, ErrClosedConnection
+	}
+	c.pending.Add(1)
+	c.mu.Unlock()
+	defer c.pending.Done()
+	return c.roundTrip(ctx, req)
+}
+
+// roundTrip sends req to the server and returns the response. It blocks until
+// a slot in the pipeline window is available, then until a response is
+// received, ctx is canceled, or the command timeout (see [WithTimeout]) elapses.
+// Once sent, a command occupies its slot until the server responds, even if
+// ctx is canceled. If the command timeout elapses after the command is sent,
+// the connection is closed, as the server may never respond.
+//
+// roundTrip reports whether req was sent to the server.
+func (c *client) roundTrip(ctx context.Context, req epp.Body) (epp.Body, bool, error) {
+	parent := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	c.lastActive.Store(time.Now().UnixNano())
+	defer func() { c.lastActive.Store(time.Now().UnixNano()) }()
+
+	err := context.Cause(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	req, id, err := c.startTransaction(req)
+	if err != nil {
+		return nil, false, err
+	}
+	select {
+	case <-ctx.Done():
+		c.endTransaction(id)
+		return nil, false, context.Cause(ctx)
+	case <-c.done:
+		c.endTransaction(id)
+		return nil, false, ErrClosedConnection
+	case c.window <- struct{}{}:
+	}
+	ch := make(chan result, 1)
+	go func() {
+		defer func() { <-c.window }()
+		defer c.endTransaction(id)
+		body, err := c.client.ExchangeEPP(context.Background(), req)
+		switch {
+		case err != nil && c.closed():
+			err = ErrClosedConnection
+		case err == nil:
+			err = c.checkTransaction(body, id)
+		}
+		ch <- result{body, err}
+	}()
+	select {
+	case <-ctx.Done():
+		if parent.Err() == nil {
+			c.Close()
+		}
+		return nil, true, context.Cause(ctx)
+	case res := <-ch:
+		return res.body, true, res.err
+	}
+}
+
+type result struct {
+	body epp.Body
+	err  error
+}
+
+// startTransaction assigns a client transaction ID to req if it is an
+// *epp.Command without one, returning the (possibly copied) command and its
+// transaction ID. It returns a [DuplicateTransactionIDError] if a command with
+// the same transaction ID is in flight.
+func (c *client) startTransaction(req epp.Body) (epp.Body, string, error) {
+	cmd, ok := req.(*epp.Command)
+	if !ok {
+		return req, "", nil
+	}
+	if cmd.ClientTransactionID == "" {
+		dup := *cmd
+		dup.ClientTransactionID = c.cfg.transactionID()
+		cmd = &dup
+	}
+	id := cmd.ClientTransactionID
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.transactions[id]; ok {
+		return nil, "", DuplicateTransactionIDError{TransactionID: id}
+	}
+	if c.transactions == nil {
+		c.transactions = make(map[string]struct{})
+	}
+	c.transactions[id] = struct{}{}
+	return cmd, id, nil
+}
+
+// endTransaction removes transaction ID id from the set of in-flight
+// transactions.
+func (c *client) endTransaction(id string) {
+	if id == "" {
+		return
+	}
+	c.mu.Lock()
+	delete(c.transactions, id)
+	c.mu.Unlock()
+}
+
+// checkTransaction verifies that a <response> to a command with transaction ID
+// id contains the same client transaction ID. A mismatch indicates the
+// session is out of sync, so the connection is closed and a
+// [TransactionIDError] is returned.
+func (c *client) checkTransaction(body epp.Body, id string) error {
+	res, ok := body.(*epp.Response)
+	if !ok || id == "" || res.TransactionID.Client == id {
+		return nil
+	}
+	c.Close()
+	return TransactionIDError{TransactionID: res.TransactionID.Client}
+}
+
+// command sends req to the server and returns the server’s <response>.
+// If the response does not contain a result with code want, the first
+// unexpected result is returned as an error.
+func (c *client) command(ctx context.Context, req epp.Body, want epp.ResultCode) (*epp.Response, error) {
+	body, err := c.exchange(ctx, req)
+	return checkResponse(body, err, want)
+}
+
+// DomainCheck describes the availability of a domain name, as reported by
+// a server in response to a <domain:check> command.
+type DomainCheck struct {
+	// Available reports whether the domain name can be provisioned.
+	Available bool
+
+	// Reason is an optional server-specific explanation of why the domain
+	// name is not available.
+	Reason string
+}
+
+// checkDomains sends a <domain:check> command for names with c and returns
+// the result for each name in the <domain:chkData> response. The client
+// transaction ID is assigned by c.
+func checkDomains(ctx context.Context, c protocol.Client, names []string) (map[string]DomainCheck, error) {
+	req := &epp.Command{
+		Action: &epp.Check{Check: &domain.Check{Names: names}},
+	}
+	body, err := c.ExchangeEPP(ctx, req)
+	res, err := checkResponse(body, err, epp.Success)
+	if err != nil {
+		return nil, err
+	}
+	var checks map[string]DomainCheck
+	for _, data := range res.Data {
+		data, ok := data.(*domain.CheckData)
+		if !ok {
+			continue
+		}
+		if checks == nil {
+			checks = make(map[string]DomainCheck, len(data.Results))
+		}
+		for _, r := range data.Results {
+			check := DomainCheck{Available: bool(r.Name.Available)}
+			if r.Reason != nil {
+				check.Reason = r.Reason.Reason
+			}
+			checks[r.Name.Name] = check
+		}
+	}
+	if checks == nil {
+		return nil, ErrUnexpectedMessage
+	}
+	return checks, nil
+}
+
+// checkResponse returns body as an *epp.Response if err is nil and each
+// result has code want. Otherwise, the first unexpected result is returned as
+// an error.
+func checkResponse(body epp.Body, err error, want epp.ResultCode) (*epp.Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	res, ok := body.(*epp.Response)
+	if !ok {
+		return nil, ErrUnexpectedMessage
+	}
+	if len(res.Results) == 0 {
+		return res, ErrUnexpectedMessage
+	}
+	for i := range res.Results {
+		if res.Results[i].Code != want {
+			return res, &res.Results[i]
+		}
+	}
+	return res, nil
 }
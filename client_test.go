@@ -0,0 +1,480 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/domainr/epp2/ns"
+	"github.com/domainr/epp2/protocol"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+func TestClientLoginLogout(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	logins := make(chan *epp.Login, 1)
+	go testServer(t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		switch a := cmd.Action.(type) {
+		case *epp.Login:
+			logins <- a
+			return testResponse(cmd, epp.Success)
+		case *epp.Logout:
+			return testResponse(cmd, epp.SuccessEnd)
+		}
+		return testResponse(cmd, epp.ErrUnimplementedCommand)
+	})
+
+	c, err := Connect(clientConn)
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	ctx := context.Background()
+	err = c.Login(ctx, "user", "password", nil)
+	if err != nil {
+		t.Fatalf("Login(): err == %v", err)
+	}
+	login := <-logins
+	if login.ClientID != "user" || login.Password != "password" {
+		t.Errorf("Login(): server received clID %q, pw %q", login.ClientID, login.Password)
+	}
+	// The client has no schema for host objects.
+	if want := []string{ns.Domain, ns.Contact}; !reflect.DeepEqual(login.Services.Objects, want) {
+		t.Errorf("Login(): server received objURI %v, expected %v", login.Services.Objects, want)
+	}
+	err = c.Logout(ctx)
+	if err != nil {
+		t.Errorf("Logout(): err == %v", err)
+	}
+}
+
+func TestClientLoginError(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	go testServer(t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		return testResponse(cmd, epp.ErrAuthentication)
+	})
+
+	c, err := Connect(clientConn)
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+	err = c.Login(context.Background(), "user", "wrong", nil)
+	r, ok := err.(*epp.Result)
+	if !ok {
+		t.Fatalf("Login(): err == %v, expected *epp.Result", err)
+	}
+	if r.Code != epp.ErrAuthentication {
+		t.Errorf("Login(): result code %04d, expected %04d", r.Code, epp.ErrAuthentication)
+	}
+}
+
+func TestClientPipeline(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	release := make(chan struct{})
+	go testServer(t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		<-release
+		return testResponse(cmd, epp.Success)
+	})
+
+	c, err := Connect(clientConn, WithPipeline(2))
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+			errs <- err
+		}()
+	}
+	for c.InFlight() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The pipeline is full, so this command should time out waiting.
+	wantErr := errors.New("pipeline full")
+	ctx2, cancel := context.WithTimeoutCause(ctx, 10*time.Millisecond, wantErr)
+	defer cancel()
+	_, err = c.ExchangeEPP(ctx2, &epp.Command{Action: &epp.Poll{}})
+	if err != wantErr {
+		t.Errorf("ExchangeEPP(): err == %v, expected %v", err, wantErr)
+	}
+	if n := c.InFlight(); n != 2 {
+		t.Errorf("InFlight() == %d, expected 2", n)
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("ExchangeEPP(): err == %v", err)
+		}
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	release := make(chan struct{})
+	defer close(release)
+	go testServer(t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		<-release
+		return testResponse(cmd, epp.Success)
+	})
+
+	c, err := Connect(clientConn, WithTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+
+	_, err = c.ExchangeEPP(context.Background(), &epp.Command{Action: &epp.Poll{}})
+	if err != context.DeadlineExceeded {
+		t.Errorf("ExchangeEPP(): err == %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	// The server may never respond, so the connection is closed and the
+	// pipeline slot is released.
+	deadline := time.Now().Add(time.Second)
+	for c.InFlight() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("InFlight() == %d, expected 0", c.InFlight())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, err = c.ExchangeEPP(context.Background(), &epp.Command{Action: &epp.Poll{}})
+	if err != ErrClosedConnection {
+		t.Errorf("ExchangeEPP(): err == %v, expected %v", err, ErrClosedConnection)
+	}
+}
+
+func TestClientCancelAfterSend(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	release := make(chan struct{})
+	received := make(chan struct{}, 1)
+	go testServer(t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		received <- struct{}{}
+		<-release
+		return testResponse(cmd, epp.Success)
+	})
+
+	c, err := Connect(clientConn, WithTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+
+	// Canceling the caller’s Context does not close the connection.
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		errs <- err
+	}()
+	<-received
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("ExchangeEPP(): err == %v, expected %v", err, context.Canceled)
+	}
+	close(release)
+	_, err = c.ExchangeEPP(context.Background(), &epp.Command{Action: &epp.Poll{}})
+	if err != nil {
+		t.Errorf("ExchangeEPP(): err == %v", err)
+	}
+}
+
+func TestClientKeepAlive(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	hellos := make(chan struct{}, 1)
+	go func() {
+		defer serverConn.Close()
+		ctx := context.Background()
+		s, err := protocol.Serve(ctx, serverConn, testGreeting)
+		if err != nil {
+			return
+		}
+		body, r, err := s.ServeEPP(ctx)
+		if err != nil {
+			return
+		}
+		if _, ok := body.(*epp.Hello); !ok {
+			t.Errorf("server received %T, expected *epp.Hello", body)
+		}
+		greeting := *testGreeting
+		greeting.ServerName = "Refreshed EPP Server"
+		_ = r.RespondEPP(ctx, &greeting)
+		hellos <- struct{}{}
+	}()
+
+	c, err := Connect(clientConn, WithKeepAlive(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+
+	select {
+	case <-hellos:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for keep-alive <hello>")
+	}
+	for {
+		cl := c.(*client)
+		cl.mu.Lock()
+		name := cl.greeting.ServerName
+		cl.mu.Unlock()
+		if name == "Refreshed EPP Server" {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClientKeepAliveClosed(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	go func() {
+		// Send a greeting, then drop the connection.
+		_, _ = protocol.Serve(context.Background(), serverConn, testGreeting)
+		serverConn.Close()
+	}()
+
+	c, err := Connect(clientConn, WithKeepAlive(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+
+	// A failed keep-alive <hello> closes the client.
+	cl := c.(*client)
+	select {
+	case <-cl.done:
+	case <-time.After(time.Second):
+		t.Fatal("client not closed after the server connection was dropped")
+	}
+}
+
+func TestClientShutdown(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	release := make(chan struct{})
+	logouts := make(chan struct{}, 1)
+	go testServer(t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		switch cmd.Action.(type) {
+		case *epp.Login:
+			return testResponse(cmd, epp.Success)
+		case *epp.Logout:
+			logouts <- struct{}{}
+			return testResponse(cmd, epp.SuccessEnd)
+		}
+		<-release
+		return testResponse(cmd, epp.Success)
+	})
+
+	c, err := Connect(clientConn)
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	ctx := context.Background()
+	err = c.Login(ctx, "user", "password", nil)
+	if err != nil {
+		t.Fatalf("Login(): err == %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		errs <- err
+	}()
+	for c.InFlight() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- c.Shutdown(ctx)
+	}()
+	for !c.(*client).isClosing() {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+	if err != ErrClosedConnection {
+		t.Errorf("ExchangeEPP(): err == %v, expected %v", err, ErrClosedConnection)
+	}
+
+	close(release)
+	if err := <-errs; err != nil {
+		t.Errorf("ExchangeEPP(): err == %v", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown(): err == %v", err)
+	}
+	select {
+	case <-logouts:
+	default:
+		t.Error("Shutdown(): <logout> not sent")
+	}
+}
+
+func TestClientTransactionID(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	release := make(chan struct{})
+	ids := make(chan string, 2)
+	go testServer(t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		ids <- cmd.ClientTransactionID
+		<-release
+		res := testResponse(cmd, epp.Success)
+		if cmd.ClientTransactionID == "bad" {
+			res.TransactionID.Client = "wrong"
+		}
+		return res
+	})
+
+	c, err := Connect(clientConn, WithPipeline(2))
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// A caller-supplied transaction ID is sent unmodified.
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}, ClientTransactionID: "bad"})
+		errs <- err
+	}()
+	if id := <-ids; id != "bad" {
+		t.Errorf("server received clTRID %q, expected %q", id, "bad")
+	}
+
+	// A duplicate in-flight transaction ID is rejected.
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}, ClientTransactionID: "bad"})
+	if err != (DuplicateTransactionIDError{TransactionID: "bad"}) {
+		t.Errorf("ExchangeEPP(): err == %v, expected DuplicateTransactionIDError", err)
+	}
+
+	// A mismatched response transaction ID is detected.
+	close(release)
+	err = <-errs
+	if err != (TransactionIDError{TransactionID: "wrong"}) {
+		t.Errorf("ExchangeEPP(): err == %v, expected TransactionIDError", err)
+	}
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+	if err != ErrClosedConnection {
+		t.Errorf("ExchangeEPP(): err == %v, expected %v", err, ErrClosedConnection)
+	}
+}
+
+func TestCheckDomains(t *testing.T) {
+	var names []string
+	c := exchangeFunc(func(ctx context.Context, req epp.Body) (epp.Body, error) {
+		cmd := req.(*epp.Command)
+		names = cmd.Action.(*epp.Check).Check.(*domain.Check).Names
+		res := testResponse(cmd, epp.Success)
+		res.Data = []epp.ResponseData{
+			&domain.CheckData{
+				Results: []domain.CheckResult{
+					{Name: domain.CheckName{Available: true, Name: "example.com"}},
+					{
+						Name:   domain.CheckName{Name: "example.net"},
+						Reason: &domain.Reason{Lang: "en", Reason: "In use"},
+					},
+				},
+			},
+		}
+		return res, nil
+	})
+
+	got, err := checkDomains(context.Background(), c, []string{"example.com", "example.net"})
+	if err != nil {
+		t.Fatalf("checkDomains(): err == %v", err)
+	}
+	if want := []string{"example.com", "example.net"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("checkDomains(): server received names %v, expected %v", names, want)
+	}
+	want := map[string]DomainCheck{
+		"example.com": {Available: true},
+		"example.net": {Available: false, Reason: "In use"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkDomains() == %v, expected %v", got, want)
+	}
+
+	// A response without <domain:chkData> is unexpected.
+	c = exchangeFunc(func(ctx context.Context, req epp.Body) (epp.Body, error) {
+		return testResponse(req.(*epp.Command), epp.Success), nil
+	})
+	_, err = checkDomains(context.Background(), c, []string{"example.com"})
+	if err != ErrUnexpectedMessage {
+		t.Errorf("checkDomains(): err == %v, expected %v", err, ErrUnexpectedMessage)
+	}
+}
+
+func TestCommandTransactionID(t *testing.T) {
+	body, err := Command(&Config{}, &epp.Poll{})
+	if err != nil {
+		t.Fatalf("Command(): err == %v", err)
+	}
+	if body.(*epp.Command).ClientTransactionID == "" {
+		t.Error("Command(): empty clTRID")
+	}
+}
+
+// exchangeFunc is a [protocol.Client] that calls f for each message.
+type exchangeFunc func(context.Context, epp.Body) (epp.Body, error)
+
+func (f exchangeFunc) ExchangeEPP(ctx context.Context, req epp.Body) (epp.Body, error) {
+	return f(ctx, req)
+}
+
+var testGreeting = &epp.Greeting{
+	ServerName: "Test EPP Server",
+	ServiceMenu: &epp.ServiceMenu{
+		Versions:  []string{epp.Version},
+		Languages: []string{"en"},
+		Objects:   []string{ns.Domain, ns.Contact, ns.Host},
+	},
+}
+
+// testServer implements a rudimentary EPP server that sends greeting, then
+// responds to each command with the response returned by f.
+func testServer(t *testing.T, conn net.Conn, greeting epp.Body, f func(*epp.Command) *epp.Response) {
+	defer conn.Close()
+	ctx := context.Background()
+	s, err := protocol.Serve(ctx, conn, greeting)
+	if err != nil {
+		return
+	}
+	for {
+		body, r, err := s.ServeEPP(ctx)
+		if err != nil {
+			return
+		}
+		var res epp.Body
+		switch body := body.(type) {
+		case *epp.Hello:
+			res = greeting
+		case *epp.Command:
+			res = f(body)
+		default:
+			t.Errorf("testServer: unexpected message: %T", body)
+			return
+		}
+		err = r.RespondEPP(ctx, res)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func testResponse(cmd *epp.Command, code epp.ResultCode) *epp.Response {
+	return &epp.Response{
+		Results: []epp.Result{{Code: code, Message: code.Message()}},
+		TransactionID: epp.TransactionID{
+			Client: cmd.ClientTransactionID,
+			Server: "server-" + cmd.ClientTransactionID,
+		},
+	}
+}
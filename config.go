@@ -1,8 +1,16 @@
 package epp
 
 import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/domainr/epp2/internal/xml"
+	"github.com/domainr/epp2/protocol"
 	"github.com/domainr/epp2/schema"
 	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/eppcom"
 )
 
 // Config describes the configuration of an EPP client or server, including EPP
@@ -60,6 +68,24 @@ type Config struct {
 	// If nil or empty, reasonable defaults will be used.
 	Schemas []schema.Schema
 
+	// ServerName is the name announced by a server in the <svID> element
+	// of its <greeting>. It is ignored by clients. If empty, a [Server]
+	// announces its Name.
+	ServerName string
+
+	// DCP is the data collection policy announced by a server in its
+	// <greeting>. It is ignored by clients. If nil, a server will announce
+	// a policy granting access to all data, collected for administrative
+	// and provisioning purposes, and shared with the registry and the
+	// public.
+	DCP *epp.DCP
+
+	// ErrorResult, if not nil, maps an error returned by a server handler
+	// to an EPP <result>. If it returns nil, the default mapping is used.
+	// A result without a message is given the default message for its code.
+	// See [ErrorResponse].
+	ErrorResult func(error) *epp.Result
+
 	// TransactionID, if not nil, returns unique values used for client or
 	// server transaction IDs. For clients, this generates command
 	// transaction IDs. For servers, this generates response transaction
@@ -72,6 +98,15 @@ type Config struct {
 	TransactionID func() string
 }
 
+// transactionID returns a new transaction ID from c.TransactionID, or from a
+// sequential source with a random prefix if c.TransactionID is nil.
+func (c *Config) transactionID() string {
+	if c.TransactionID != nil {
+		return c.TransactionID()
+	}
+	return defaultSeqSource().ID()
+}
+
 // Copy deep copy of c.
 func (c Config) Copy() Config {
 	c.Versions = copySlice(c.Versions)
@@ -79,6 +114,11 @@ func (c Config) Copy() Config {
 	c.Objects = copySlice(c.Objects)
 	c.Extensions = copySlice(c.Extensions)
 	c.UnannouncedExtensions = copySlice(c.UnannouncedExtensions)
+	if c.DCP != nil {
+		dcp := *c.DCP
+		dcp.Statements = slices.Clone(dcp.Statements)
+		c.DCP = &dcp
+	}
 	return c
 }
 
@@ -97,9 +137,197 @@ func copySlice(s []string) []string {
 // error. The resulting Config is suitable for creating EPP elements
 // transmittable to the server that sent the Greeting.
 //
-// TODO: implement this function.
+// The returned Config contains exactly one version and language. The language
+// is the first of the client’s preferred languages supported by the server,
+// or the first language announced by the server if none match. If cfg.Objects
+// or cfg.Extensions is nil, the objects or extensions announced by the server
+// with a namespace in cfg.Schemas are used. Extensions are reduced to a single
+// version per extension family (e.g. fee-0.8 and fee-1.0).
+//
+// The error returned may be a [NegotiationError] identifying the element
+// without a mutual value.
 func ConfigForGreeting(cfg *Config, greeting *epp.Greeting) (*Config, error) {
-	return cfg, nil // TODO
+	var menu epp.ServiceMenu
+	if greeting != nil && greeting.ServiceMenu != nil {
+		menu = *greeting.ServiceMenu
+	}
+	var announced []string
+	if menu.ServiceExtension != nil {
+		announced = menu.ServiceExtension.Extensions
+	}
+
+	c := cfg.Copy()
+
+	versions := cfg.Versions
+	if versions == nil {
+		versions = defaultVersions
+	}
+	c.Versions = intersect(versions, menu.Versions)
+	if len(c.Versions) == 0 {
+		return nil, NegotiationError{Element: "version", Values: versions}
+	}
+	c.Versions = c.Versions[:1]
+
+	languages := cfg.Languages
+	if languages == nil {
+		languages = defaultLanguages
+	}
+	c.Languages = intersect(languages, menu.Languages)
+	switch {
+	case len(c.Languages) > 0:
+		c.Languages = c.Languages[:1]
+	case len(menu.Languages) > 0:
+		c.Languages = []string{menu.Languages[0]}
+	default:
+		c.Languages = []string{languages[0]}
+	}
+
+	supported := schemaNamespaces(cfg.Schemas)
+	if cfg.Objects == nil {
+		c.Objects = intersect(menu.Objects, supported)
+	} else {
+		c.Objects = intersect(cfg.Objects, menu.Objects)
+	}
+	if len(c.Objects) == 0 {
+		objects := cfg.Objects
+		if objects == nil {
+			objects = defaultObjects(cfg.Schemas)
+		}
+		return nil, NegotiationError{Element: "objURI", Values: objects}
+	}
+
+	if cfg.Extensions == nil {
+		c.Extensions = latestExtensions(intersect(announced, supported))
+	} else {
+		c.Extensions = firstExtensions(intersect(cfg.Extensions, announced))
+	}
+
+	// Skip unannounced extensions that were negotiated normally.
+	c.UnannouncedExtensions = nil
+	for _, ext := range cfg.UnannouncedExtensions {
+		if !slices.Contains(c.Extensions, ext) {
+			c.UnannouncedExtensions = append(c.UnannouncedExtensions, ext)
+		}
+	}
+
+	return &c, nil
+}
+
+var (
+	defaultVersions  = []string{epp.Version}
+	defaultLanguages = []string{"en"}
+)
+
+// difference returns the values in a that are not present in b.
+func difference(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if !slices.Contains(b, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// intersect returns the values in a that are also present in b, in the order
+// they appear in a.
+func intersect(a, b []string) []string {
+	var out []string
+	for _, v := range a {
+		if slices.Contains(b, v) && !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// firstExtensions returns the first extension URI of each extension family
+// in exts, preserving order.
+func firstExtensions(exts []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, uri := range exts {
+		family, _ := splitExtension(uri)
+		if seen[family] {
+			continue
+		}
+		seen[family] = true
+		out = append(out, uri)
+	}
+	return out
+}
+
+// latestExtensions returns the highest version of each extension family in
+// exts, preserving order.
+func latestExtensions(exts []string) []string {
+	latest := make(map[string]string)
+	for _, uri := range exts {
+		family, version := splitExtension(uri)
+		prev, ok := latest[family]
+		if !ok {
+			latest[family] = uri
+			continue
+		}
+		_, prevVersion := splitExtension(prev)
+		if compareVersions(version, prevVersion) > 0 {
+			latest[family] = uri
+		}
+	}
+	var out []string
+	for _, uri := range exts {
+		family, _ := splitExtension(uri)
+		if latest[family] == uri {
+			out = append(out, uri)
+			delete(latest, family)
+		}
+	}
+	return out
+}
+
+// splitExtension splits an extension namespace URI into a family name and
+// version. For example, urn:ietf:params:xml:ns:epp:fee-1.0 returns
+// ("fee", "1.0"). A URI without a version suffix returns (uri, "").
+func splitExtension(uri string) (family, version string) {
+	i := strings.LastIndexByte(uri, '-')
+	if i < 0 || !isVersion(uri[i+1:]) {
+		return uri, ""
+	}
+	family, version = uri[:i], uri[i+1:]
+	if j := strings.LastIndexAny(family, ":/"); j >= 0 {
+		family = family[j+1:]
+	}
+	return family, version
+}
+
+func isVersion(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares dotted numeric version strings a and b,
+// returning -1, 0, or +1.
+func compareVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if c := cmp.Compare(na, nb); c != 0 {
+			return c
+		}
+	}
+	return 0
 }
 
 // ConfigForLogin returns a server-centric Config sharing the mutual
@@ -109,9 +337,103 @@ func ConfigForGreeting(cfg *Config, greeting *epp.Greeting) (*Config, error) {
 // the client that generated the Login.
 //
 // The error returned may be an *epp.Result which can be transmitted back to an
-// EPP client in an epp.Response.
-//
-// TODO: implement this function.
+// EPP client in an epp.Response. The Result will contain a Value for each
+// offending element in the Login.
 func ConfigForLogin(cfg *Config, login *epp.Login) (*Config, error) {
-	return cfg, nil // TODO
+	c := cfg.Copy()
+
+	versions := cfg.Versions
+	if versions == nil {
+		versions = defaultVersions
+	}
+	if !slices.Contains(versions, login.Options.Version) {
+		return nil, elementResult(epp.ErrUnimplementedVersion, "version", login.Options.Version)
+	}
+	c.Versions = []string{login.Options.Version}
+
+	languages := cfg.Languages
+	if languages == nil {
+		languages = defaultLanguages
+	}
+	switch {
+	case login.Options.Lang == "":
+		c.Languages = languages[:1]
+	case slices.Contains(languages, login.Options.Lang):
+		c.Languages = []string{login.Options.Lang}
+	default:
+		return nil, elementResult(epp.ErrUnimplementedOption, "lang", login.Options.Lang)
+	}
+
+	objects := cfg.Objects
+	if objects == nil {
+		objects = defaultObjects(cfg.Schemas)
+	}
+	if unknown := difference(login.Services.Objects, objects); len(unknown) > 0 {
+		return nil, elementResult(epp.ErrUnimplementedObject, "objURI", unknown...)
+	}
+	c.Objects = copySlice(login.Services.Objects)
+
+	var exts []string
+	if login.Services.ServiceExtension != nil {
+		exts = login.Services.ServiceExtension.Extensions
+	}
+	if unknown := difference(exts, cfg.Extensions); len(unknown) > 0 {
+		return nil, elementResult(epp.ErrUnimplementedExtension, "extURI", unknown...)
+	}
+	c.Extensions = copySlice(exts)
+	c.UnannouncedExtensions = nil
+
+	return &c, nil
+}
+
+// elementResult returns an *epp.Result with code and a Value for each element
+// with the specified local name and values.
+func elementResult(code epp.ResultCode, name string, values ...string) *epp.Result {
+	r := &epp.Result{
+		Code:    code,
+		Message: code.Message(),
+	}
+	for _, v := range values {
+		r.Values = append(r.Values, &epp.ElementValue{
+			Element: epp.Element{
+				XMLName: xml.Name{Space: epp.NS, Local: name},
+				Value:   v,
+			},
+		})
+	}
+	return r
+}
+
+// schemaNamespaces returns each object and extension namespace URI recognized
+// by schemas. If schemas is empty, the default schemas will be used.
+func schemaNamespaces(schemas []schema.Schema) []string {
+	if len(schemas) == 0 {
+		schemas = protocol.DefaultSchemas()
+	}
+	var nss []string
+	for _, s := range schemas {
+		for _, ns := range s.SchemaNS() {
+			if ns != epp.NS && ns != eppcom.NS {
+				nss = append(nss, ns)
+			}
+		}
+	}
+	return nss
+}
+
+// defaultObjects returns the preferred namespace URI of each object schema in
+// schemas. If schemas is empty, the default schemas will be used.
+func defaultObjects(schemas []schema.Schema) []string {
+	if len(schemas) == 0 {
+		schemas = protocol.DefaultSchemas()
+	}
+	var objects []string
+	for _, s := range schemas {
+		nss := s.SchemaNS()
+		if len(nss) == 0 || nss[0] == epp.NS || nss[0] == eppcom.NS {
+			continue
+		}
+		objects = append(objects, nss[0])
+	}
+	return objects
 }
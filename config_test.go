@@ -0,0 +1,256 @@
+package epp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/domainr/epp2/internal/xml"
+	"github.com/domainr/epp2/ns"
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+// testSchema is a [schema.Schema] that recognizes a list of namespace URIs,
+// but does not resolve any types.
+type testSchema []string
+
+func (testSchema) SchemaName() string           { return "test" }
+func (s testSchema) SchemaNS() []string         { return s }
+func (testSchema) ResolveXML(name xml.Name) any { return nil }
+
+func TestConfigForGreeting(t *testing.T) {
+	menu := &epp.ServiceMenu{
+		Versions:  []string{"1.0"},
+		Languages: []string{"en", "fr"},
+		Objects:   []string{ns.Domain, ns.Contact, ns.Host},
+		ServiceExtension: &epp.ServiceExtension{
+			Extensions: []string{ns.Fee08, ns.Fee10, ns.IDN, ns.Fee09},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		menu    *epp.ServiceMenu
+		want    *Config
+		wantErr error
+	}{
+		{
+			`defaults`,
+			Config{},
+			menu,
+			&Config{
+				Versions:  []string{"1.0"},
+				Languages: []string{"en"},
+				Objects:   []string{ns.Domain, ns.Contact},
+			},
+			nil,
+		},
+		{
+			`preferred language`,
+			Config{Languages: []string{"de", "fr", "en"}},
+			menu,
+			&Config{
+				Versions:  []string{"1.0"},
+				Languages: []string{"fr"},
+				Objects:   []string{ns.Domain, ns.Contact},
+			},
+			nil,
+		},
+		{
+			`fallback language`,
+			Config{Languages: []string{"de"}},
+			menu,
+			&Config{
+				Versions:  []string{"1.0"},
+				Languages: []string{"en"},
+				Objects:   []string{ns.Domain, ns.Contact},
+			},
+			nil,
+		},
+		{
+			`supported schemas`,
+			Config{Schemas: []schema.Schema{domain.Schema, testSchema{ns.Host, ns.Fee08, ns.Fee10, ns.IDN}}},
+			menu,
+			&Config{
+				Versions:   []string{"1.0"},
+				Languages:  []string{"en"},
+				Objects:    []string{ns.Domain, ns.Host},
+				Extensions: []string{ns.Fee10, ns.IDN},
+				Schemas:    []schema.Schema{domain.Schema, testSchema{ns.Host, ns.Fee08, ns.Fee10, ns.IDN}},
+			},
+			nil,
+		},
+		{
+			`no supported objects`,
+			Config{Schemas: []schema.Schema{testSchema{"urn:example:unknown"}}},
+			menu,
+			nil,
+			NegotiationError{Element: "objURI", Values: []string{"urn:example:unknown"}},
+		},
+		{
+			`client objects and extensions`,
+			Config{
+				Objects:               []string{ns.Host, ns.Domain, "urn:example:unknown"},
+				Extensions:            []string{ns.Fee09, ns.Fee10, ns.SecDNS},
+				UnannouncedExtensions: []string{ns.RGP, ns.Fee09},
+			},
+			menu,
+			&Config{
+				Versions:              []string{"1.0"},
+				Languages:             []string{"en"},
+				Objects:               []string{ns.Host, ns.Domain},
+				Extensions:            []string{ns.Fee09},
+				UnannouncedExtensions: []string{ns.RGP},
+			},
+			nil,
+		},
+		{
+			`no mutual version`,
+			Config{Versions: []string{"2.0"}},
+			menu,
+			nil,
+			NegotiationError{Element: "version", Values: []string{"2.0"}},
+		},
+		{
+			`no mutual objects`,
+			Config{Objects: []string{"urn:example:unknown"}},
+			menu,
+			nil,
+			NegotiationError{Element: "objURI", Values: []string{"urn:example:unknown"}},
+		},
+		{
+			`empty greeting`,
+			Config{},
+			nil,
+			nil,
+			NegotiationError{Element: "version", Values: []string{"1.0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConfigForGreeting(&tt.cfg, &epp.Greeting{ServiceMenu: tt.menu})
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("ConfigForGreeting(): err == %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConfigForGreeting():\nGot:  %+v\nWant: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigForLogin(t *testing.T) {
+	cfg := Config{
+		Languages:  []string{"en", "fr"},
+		Objects:    []string{ns.Domain, ns.Contact},
+		Extensions: []string{ns.Fee10, ns.IDN},
+	}
+
+	tests := []struct {
+		name     string
+		login    epp.Login
+		want     *Config
+		wantCode epp.ResultCode
+		wantVals []string
+	}{
+		{
+			`simple login`,
+			epp.Login{
+				Options:  epp.Options{Version: "1.0"},
+				Services: epp.Services{Objects: []string{ns.Domain}},
+			},
+			&Config{
+				Versions:  []string{"1.0"},
+				Languages: []string{"en"},
+				Objects:   []string{ns.Domain},
+			},
+			0,
+			nil,
+		},
+		{
+			`login with lang and extensions`,
+			epp.Login{
+				Options: epp.Options{Version: "1.0", Lang: "fr"},
+				Services: epp.Services{
+					Objects:          []string{ns.Contact, ns.Domain},
+					ServiceExtension: &epp.ServiceExtension{Extensions: []string{ns.IDN}},
+				},
+			},
+			&Config{
+				Versions:   []string{"1.0"},
+				Languages:  []string{"fr"},
+				Objects:    []string{ns.Contact, ns.Domain},
+				Extensions: []string{ns.IDN},
+			},
+			0,
+			nil,
+		},
+		{
+			`unsupported version`,
+			epp.Login{Options: epp.Options{Version: "2.0"}},
+			nil,
+			epp.ErrUnimplementedVersion,
+			[]string{"2.0"},
+		},
+		{
+			`unsupported language`,
+			epp.Login{Options: epp.Options{Version: "1.0", Lang: "de"}},
+			nil,
+			epp.ErrUnimplementedOption,
+			[]string{"de"},
+		},
+		{
+			`unknown objects`,
+			epp.Login{
+				Options:  epp.Options{Version: "1.0"},
+				Services: epp.Services{Objects: []string{ns.Domain, ns.Host, "urn:example:unknown"}},
+			},
+			nil,
+			epp.ErrUnimplementedObject,
+			[]string{ns.Host, "urn:example:unknown"},
+		},
+		{
+			`unknown extension`,
+			epp.Login{
+				Options: epp.Options{Version: "1.0"},
+				Services: epp.Services{
+					Objects:          []string{ns.Domain},
+					ServiceExtension: &epp.ServiceExtension{Extensions: []string{ns.Fee08}},
+				},
+			},
+			nil,
+			epp.ErrUnimplementedExtension,
+			[]string{ns.Fee08},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ConfigForLogin(&cfg, &tt.login)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConfigForLogin():\nGot:  %+v\nWant: %+v", got, tt.want)
+			}
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Errorf("ConfigForLogin(): err == %v", err)
+				}
+				return
+			}
+			r, ok := err.(*epp.Result)
+			if !ok {
+				t.Fatalf("ConfigForLogin(): err == %v, expected *epp.Result", err)
+			}
+			if r.Code != tt.wantCode {
+				t.Errorf("ConfigForLogin(): result code %04d, expected %04d", r.Code, tt.wantCode)
+			}
+			var vals []string
+			for _, v := range r.Values {
+				vals = append(vals, v.(*epp.ElementValue).Element.Value)
+			}
+			if !reflect.DeepEqual(vals, tt.wantVals) {
+				t.Errorf("ConfigForLogin(): result values %v, expected %v", vals, tt.wantVals)
+			}
+		})
+	}
+}
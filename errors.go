@@ -1,5 +1,7 @@
 package epp
 
+import "strings"
+
 // Error is the interface implemented by all errors in this package.
 type Error interface {
 	eppError()
@@ -19,7 +21,43 @@ const ErrClosedConnection stringError = "operation on closed connection"
 // ErrServerClosed indicates a [Server] has shut down or closed.
 const ErrServerClosed stringError = "server closed"
 
-// TransactionIDError indicates an invalid transaction ID.
+// ErrIdleTimeout indicates a [Server] closed an idle session.
+// See [Server].IdleTimeout.
+const ErrIdleTimeout stringError = "session idle timeout"
+
+// ErrSessionExpired indicates a [Server] closed a session that exceeded its
+// maximum lifetime. See [Server].MaxLifetime.
+const ErrSessionExpired stringError = "session expired"
+
+// ErrNoCommand indicates a server attempted to send a response without a
+// corresponding command from the client.
+const ErrNoCommand stringError = "no command to respond to"
+
+// ErrUnexpectedMessage indicates an EPP peer sent an unexpected or malformed message,
+// such as a <response> without a <result>.
+const ErrUnexpectedMessage stringError = "unexpected message"
+
+// AmbiguousError indicates a command may have been sent to a server, but the
+// connection failed before a response was received. The server may or may not
+// have executed the command.
+type AmbiguousError struct {
+	Err error
+}
+
+func (AmbiguousError) eppError() {}
+
+// Error implements the error interface.
+func (err AmbiguousError) Error() string {
+	return "epp: command outcome unknown: " + err.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (err AmbiguousError) Unwrap() error {
+	return err.Err
+}
+
+// TransactionIDError indicates an invalid transaction ID, such as a response
+// with a client transaction ID that does not match the command.
 type TransactionIDError struct {
 	TransactionID string
 }
@@ -28,7 +66,7 @@ func (TransactionIDError) eppError() {}
 
 // Error implements the error interface.
 func (err TransactionIDError) Error() string {
-	return "epp: invalid transaction transaction ID: " + err.TransactionID
+	return "epp: invalid transaction ID: " + err.TransactionID
 }
 
 // DuplicateTransactionIDError indicates a duplicate transaction ID.
@@ -40,3 +78,21 @@ func (DuplicateTransactionIDError) eppError() {}
 func (err DuplicateTransactionIDError) Error() string {
 	return "epp: duplicate transaction ID: " + err.TransactionID
 }
+
+// NegotiationError indicates an EPP client and server do not share a mutual
+// set of capabilities, such as an EPP version or object type.
+type NegotiationError struct {
+	// Element is the name of the EPP element that has no mutual value,
+	// e.g. "version" or "objURI".
+	Element string
+
+	// Values contains the locally supported values for Element.
+	Values []string
+}
+
+func (NegotiationError) eppError() {}
+
+// Error implements the error interface.
+func (err NegotiationError) Error() string {
+	return "epp: no mutually supported <" + err.Element + "> in [" + strings.Join(err.Values, " ") + "]"
+}
@@ -0,0 +1,75 @@
+package epp
+
+import (
+	"context"
+
+	"github.com/domainr/epp2/schema/epp"
+)
+
+// A Handler responds to an EPP command.
+//
+// HandleEPP should return a <response> to cmd. If HandleEPP returns an error,
+// the error is converted into an EPP error response with [ErrorResponse].
+//
+// The supplied Context is the session Context, and is canceled if the client
+// disconnects.
+type Handler interface {
+	HandleEPP(ctx context.Context, cmd *epp.Command) (*epp.Response, error)
+}
+
+// The HandlerFunc type is an adapter to allow the use of ordinary functions as
+// EPP handlers.
+type HandlerFunc func(ctx context.Context, cmd *epp.Command) (*epp.Response, error)
+
+// HandleEPP calls f(ctx, cmd).
+func (f HandlerFunc) HandleEPP(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+	return f(ctx, cmd)
+}
+
+// ServeCommands returns a function suitable for [Server].Handler that reads
+// each command from a [Session] and responds with the response returned by h.
+// Errors, including malformed commands, are answered with [ErrorResponse]
+// using the [Server] Config.
+//
+// The Context passed to h carries per-session state used by [Middleware] such
+// as [RequireLogin].
+func ServeCommands(h Handler) func(Session) error {
+	return func(sess Session) error {
+		ctx := sess.Context()
+		if contextState(ctx) == nil {
+			ctx = withState(ctx)
+		}
+		cfg := sessionConfig(sess)
+		for {
+			cmd, err := sess.ReadCommand()
+			if err != nil {
+				if ctx.Err() != nil {
+					return err
+				}
+				err = sess.WriteResponse(errorResponse(cfg, err))
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			res, err := h.HandleEPP(ctx, cmd)
+			if err != nil {
+				res = errorResponse(cfg, err)
+			} else if res == nil {
+				res = errorResponse(cfg, epp.ErrCommandFailed)
+			}
+			err = sess.WriteResponse(res)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sessionConfig returns the Config of the [Server] that created sess.
+func sessionConfig(sess Session) *Config {
+	if s, ok := sess.(interface{ config() *Config }); ok {
+		return s.config()
+	}
+	return &Config{}
+}
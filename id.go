@@ -4,9 +4,22 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"strconv"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// defaultSeqSource returns a process-wide seqSource, used to generate
+// transaction IDs when [Config].TransactionID is nil.
+var defaultSeqSource = sync.OnceValue(func() *seqSource {
+	s, err := newSeqSource("")
+	if err != nil {
+		// Fall back to a time-based prefix if crypto/rand is unavailable.
+		s, _ = newSeqSource(strconv.FormatInt(time.Now().UnixNano(), 36) + "-")
+	}
+	return s
+})
+
 type seqSource struct {
 	prefix string
 	n      atomic.Uint64
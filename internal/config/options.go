@@ -27,6 +27,10 @@ type Config struct {
 	Timeout   time.Duration
 	Pipeline  int
 
+	// Reconnect options
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
 	// EPP options
 	Versions              []string
 	Objects               []string
@@ -50,6 +54,10 @@ func (cfg *Config) Clone() *Config {
 		Timeout:   cfg.Timeout,
 		Pipeline:  cfg.Pipeline,
 
+		// Reconnect options
+		Backoff:    cfg.Backoff,
+		MaxBackoff: cfg.MaxBackoff,
+
 		// EPP options
 		Versions:              slices.Clone(cfg.Versions),
 		Objects:               slices.Clone(cfg.Objects),
@@ -78,6 +86,9 @@ func (cfg *Config) Join(opts ...Options) {
 			cfg.TLSConfig = (*tls.Config)(src)
 		case Pipeline:
 			cfg.Pipeline = int(src)
+		case Backoff:
+			cfg.Backoff = src.Initial
+			cfg.MaxBackoff = src.Max
 		case Schemas:
 			cfg.Schemas = append(cfg.Schemas, schema.Schemas(src)...)
 		}
@@ -97,13 +108,14 @@ func GetOption[T any, F Setter[T]](opts Options, setter F) (T, bool) {
 }
 
 type (
-	Context   struct{ context.Context } // epp.WithContext
-	KeepAlive time.Duration             // epp.WithKeepAlive
-	Timeout   time.Duration             // epp.WithTimeout
-	Dialer    struct{ ContextDialer }   // epp.WithDialer
-	TLSConfig tls.Config                // epp.WithTLS
-	Pipeline  int                       // epp.WithPipeline
-	Schemas   schema.Schemas            // epp.WithSchema
+	Context   struct{ context.Context }            // epp.WithContext
+	KeepAlive time.Duration                        // epp.WithKeepAlive
+	Timeout   time.Duration                        // epp.WithTimeout
+	Dialer    struct{ ContextDialer }              // epp.WithDialer
+	TLSConfig tls.Config                           // epp.WithTLS
+	Pipeline  int                                  // epp.WithPipeline
+	Backoff   struct{ Initial, Max time.Duration } // epp.WithBackoff
+	Schemas   schema.Schemas                       // epp.WithSchema
 )
 
 func (Context) EPPOptions(internal.Internal)    {}
@@ -112,6 +124,7 @@ func (Timeout) EPPOptions(internal.Internal)    {}
 func (Dialer) EPPOptions(internal.Internal)     {}
 func (*TLSConfig) EPPOptions(internal.Internal) {}
 func (Pipeline) EPPOptions(internal.Internal)   {}
+func (Backoff) EPPOptions(internal.Internal)    {}
 func (Schemas) EPPOptions(internal.Internal)    {}
 
 // ContextDialer is any type with a DialContext method that returns ([net.Conn], [error]).
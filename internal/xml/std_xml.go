@@ -11,6 +11,8 @@ type StartElement = xml.StartElement
 type EndElement = xml.EndElement
 type Encoder = xml.Encoder
 type Decoder = xml.Decoder
+type SyntaxError = xml.SyntaxError
+type UnmarshalError = xml.UnmarshalError
 
 var NewEncoder = xml.NewEncoder
 var NewDecoder = xml.NewDecoder
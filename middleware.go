@@ -0,0 +1,209 @@
+package epp
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/domainr/epp2/schema/epp"
+)
+
+// Middleware wraps a [Handler] to add behavior before or after it handles a
+// command.
+type Middleware func(Handler) Handler
+
+// Chain returns h wrapped by each Middleware in m. The first Middleware is the
+// outermost, and sees each command first.
+func Chain(h Handler, m ...Middleware) Handler {
+	for i := len(m) - 1; i >= 0; i-- {
+		h = m[i](h)
+	}
+	return h
+}
+
+// Recover is [Middleware] that recovers from a panic in the next Handler,
+// logs it with [slog.Default], and responds with 2400 (command failed).
+func Recover(next Handler) Handler {
+	return HandlerFunc(func(ctx context.Context, cmd *epp.Command) (res *epp.Response, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				slog.Default().ErrorContext(ctx, "epp: panic serving command",
+					"panic", v,
+					"clTRID", cmd.ClientTransactionID,
+					"stack", string(debug.Stack()))
+				res, err = nil, epp.ErrCommandFailed
+			}
+		}()
+		return next.HandleEPP(ctx, cmd)
+	})
+}
+
+// RequireLogin is [Middleware] that rejects every command except <login>
+// with 2002 (command use error) until the session has logged in. A session is
+// logged in after a successful response to <login>, and logged out after a
+// successful response to <logout>.
+//
+// RequireLogin tracks login state per session, and requires the Context
+// supplied by [ServeCommands]. The client ID of a logged-in session is
+// available to subsequent handlers via [ClientID]. Sessions created by a
+// [Server] enforce login themselves, so RequireLogin is only needed for
+// handlers served by other means.
+func RequireLogin(next Handler) Handler {
+	return HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+		st := contextState(ctx)
+		switch a := cmd.Action.(type) {
+		case *epp.Login:
+			res, err := next.HandleEPP(ctx, cmd)
+			if st != nil && succeeded(res, err) {
+				st.login(a.ClientID, nil)
+			}
+			return res, err
+		case *epp.Logout:
+			if _, ok := st.clientID(); !ok {
+				return nil, epp.ErrCommandUse
+			}
+			res, err := next.HandleEPP(ctx, cmd)
+			if succeeded(res, err) {
+				st.logout()
+			}
+			return res, err
+		}
+		if _, ok := st.clientID(); !ok {
+			return nil, epp.ErrCommandUse
+		}
+		return next.HandleEPP(ctx, cmd)
+	})
+}
+
+// LogTransactions returns [Middleware] that logs each transaction to logger,
+// including the command action, transaction IDs, result code, and the time
+// taken to handle the command. If logger is nil, [slog.Default] is used.
+func LogTransactions(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+			start := time.Now()
+			res, err := next.HandleEPP(ctx, cmd)
+			duration := time.Since(start)
+
+			attrs := []slog.Attr{
+				slog.String("action", xmlName(cmd.Action).Local),
+				slog.String("clTRID", cmd.ClientTransactionID),
+				slog.Duration("duration", duration),
+			}
+			level := slog.LevelInfo
+			if err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.Any("error", err))
+			} else if res != nil {
+				if len(res.Results) > 0 {
+					attrs = append(attrs, slog.Int("code", int(res.Results[0].Code)))
+				}
+				attrs = append(attrs, slog.String("svTRID", res.TransactionID.Server))
+			}
+			if id, ok := ClientID(ctx); ok {
+				attrs = append(attrs, slog.String("clID", id))
+			}
+			l.LogAttrs(ctx, level, "epp: transaction", attrs...)
+			return res, err
+		})
+	}
+}
+
+// ClientID returns the client identifier of the logged-in session associated
+// with ctx, such as the Context of a [Server] session. It reports false if the
+// session has not logged in, or if login state is not tracked (see
+// [RequireLogin]).
+func ClientID(ctx context.Context) (string, bool) {
+	return contextState(ctx).clientID()
+}
+
+// LoginConfig returns the Config negotiated by the <login> of the session
+// associated with ctx, such as the Context of a [Server] session. It describes
+// the objects and extensions the client may use. LoginConfig returns nil if
+// the session has not logged in, or if the session was not created by a
+// [Server].
+func LoginConfig(ctx context.Context) *Config {
+	cfg := contextState(ctx).config()
+	if cfg == nil {
+		return nil
+	}
+	c := cfg.Copy()
+	return &c
+}
+
+// succeeded reports whether a handler result represents a successful
+// response.
+func succeeded(res *epp.Response, err error) bool {
+	if err != nil || res == nil {
+		return false
+	}
+	for _, r := range res.Results {
+		if r.Code.IsError() {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionState holds per-session state shared by handlers.
+type sessionState struct {
+	mu       sync.Mutex
+	loggedIn bool
+	id       string
+	cfg      *Config // negotiated at login, nil if unknown
+}
+
+type stateKey struct{}
+
+func withState(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stateKey{}, &sessionState{})
+}
+
+func contextState(ctx context.Context) *sessionState {
+	st, _ := ctx.Value(stateKey{}).(*sessionState)
+	return st
+}
+
+func (st *sessionState) login(clientID string, cfg *Config) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.loggedIn = true
+	st.id = clientID
+	st.cfg = cfg
+}
+
+func (st *sessionState) logout() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.loggedIn = false
+	st.id = ""
+	st.cfg = nil
+}
+
+// clientID returns the logged-in client ID. It is safe to call on a nil
+// *sessionState.
+func (st *sessionState) clientID() (string, bool) {
+	if st == nil {
+		return "", false
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.id, st.loggedIn
+}
+
+// config returns the Config negotiated at login, or nil. It is safe to call on
+// a nil *sessionState.
+func (st *sessionState) config() *Config {
+	if st == nil {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.cfg
+}
@@ -0,0 +1,90 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/domainr/epp2/schema/epp"
+)
+
+func TestChain(t *testing.T) {
+	var calls []string
+	m := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+				calls = append(calls, name)
+				return next.HandleEPP(ctx, cmd)
+			})
+		}
+	}
+	h := Chain(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+		calls = append(calls, "handler")
+		return testResponse(cmd, epp.Success), nil
+	}), m("a"), m("b"))
+	_, _ = h.HandleEPP(context.Background(), &epp.Command{Action: &epp.Poll{}})
+	if got, want := strings.Join(calls, ","), "a,b,handler"; got != want {
+		t.Errorf("Chain(): calls == %s, expected %s", got, want)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	h := Recover(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+		panic("oops")
+	}))
+	_, err := h.HandleEPP(context.Background(), &epp.Command{Action: &epp.Poll{}})
+	if err != epp.ErrCommandFailed {
+		t.Errorf("HandleEPP(): err == %v, expected %v", err, epp.ErrCommandFailed)
+	}
+}
+
+func TestRequireLogin(t *testing.T) {
+	h := RequireLogin(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+		if login, ok := cmd.Action.(*epp.Login); ok && login.Password != "password" {
+			return nil, epp.ErrAuthentication
+		}
+		return testResponse(cmd, epp.Success), nil
+	}))
+	ctx := withState(context.Background())
+
+	tests := []struct {
+		name    string
+		action  epp.Action
+		wantErr error
+		wantID  string
+	}{
+		{`poll before login`, &epp.Poll{}, epp.ErrCommandUse, ""},
+		{`logout before login`, &epp.Logout{}, epp.ErrCommandUse, ""},
+		{`failed login`, &epp.Login{ClientID: "user", Password: "wrong"}, epp.ErrAuthentication, ""},
+		{`login`, &epp.Login{ClientID: "user", Password: "password"}, nil, "user"},
+		{`poll after login`, &epp.Poll{}, nil, "user"},
+		{`logout`, &epp.Logout{}, nil, ""},
+		{`poll after logout`, &epp.Poll{}, epp.ErrCommandUse, ""},
+	}
+	for _, tt := range tests {
+		_, err := h.HandleEPP(ctx, &epp.Command{Action: tt.action})
+		if err != tt.wantErr {
+			t.Errorf("%s: err == %v, expected %v", tt.name, err, tt.wantErr)
+		}
+		if id, _ := ClientID(ctx); id != tt.wantID {
+			t.Errorf("%s: ClientID() == %q, expected %q", tt.name, id, tt.wantID)
+		}
+	}
+}
+
+func TestLogTransactions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	h := LogTransactions(logger)(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+		return testResponse(cmd, epp.SuccessNoMessages), nil
+	}))
+	_, _ = h.HandleEPP(context.Background(), &epp.Command{Action: &epp.Poll{}, ClientTransactionID: "abc"})
+	got := buf.String()
+	for _, want := range []string{"action=poll", "clTRID=abc", "code=1300", "svTRID=server-abc", "duration="} {
+		if !strings.Contains(got, want) {
+			t.Errorf("LogTransactions(): log %q missing %q", got, want)
+		}
+	}
+}
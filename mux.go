@@ -0,0 +1,154 @@
+package epp
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/domainr/epp2/internal/xml"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+// ServeMux is an EPP command multiplexer. It matches each command against a
+// list of registered handlers by command action (e.g. "check" or "info") and
+// object namespace (e.g. domain.NS), and calls the matching handler.
+//
+// A handler registered with an empty namespace matches any object for that
+// action, and is used for commands without an object, such as <login> or
+// <poll>. A handler registered for a specific namespace takes precedence.
+//
+// If no handler is registered for an action, ServeMux responds with 2101
+// (unimplemented command). If handlers are registered for an action, but not
+// for the command's object namespace, ServeMux responds with 2307
+// (unimplemented object service).
+//
+// The zero value is ready to use. A ServeMux is safe to use from multiple
+// goroutines.
+type ServeMux struct {
+	mu       sync.RWMutex
+	handlers map[muxKey]Handler
+	actions  map[string]struct{}
+}
+
+var _ Handler = &ServeMux{}
+
+type muxKey struct {
+	action string
+	ns     string
+}
+
+// NewServeMux allocates and returns a new [ServeMux].
+func NewServeMux() *ServeMux {
+	return &ServeMux{}
+}
+
+// Handle registers the handler for action and object namespace ns.
+// If ns is empty, h handles action for any object.
+// Handle panics if a handler already exists for action and ns.
+func (mux *ServeMux) Handle(action, ns string, h Handler) {
+	if action == "" {
+		panic("epp: empty action")
+	}
+	if h == nil {
+		panic("epp: nil handler")
+	}
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if mux.handlers == nil {
+		mux.handlers = make(map[muxKey]Handler)
+		mux.actions = make(map[string]struct{})
+	}
+	k := muxKey{action, ns}
+	if _, ok := mux.handlers[k]; ok {
+		panic("epp: multiple registrations for " + action + " " + ns)
+	}
+	mux.handlers[k] = h
+	mux.actions[action] = struct{}{}
+}
+
+// HandleFunc registers the handler function for action and object namespace ns.
+func (mux *ServeMux) HandleFunc(action, ns string, f func(context.Context, *epp.Command) (*epp.Response, error)) {
+	mux.Handle(action, ns, HandlerFunc(f))
+}
+
+// Handler returns the handler to use for cmd. If there is no registered
+// handler for cmd, Handler returns a handler that responds with an
+// appropriate EPP error.
+func (mux *ServeMux) Handler(cmd *epp.Command) Handler {
+	action := xmlName(cmd.Action).Local
+	ns := xmlName(commandObject(cmd.Action)).Space
+
+	mux.mu.RLock()
+	defer mux.mu.RUnlock()
+	if h, ok := mux.handlers[muxKey{action, ns}]; ok {
+		return h
+	}
+	if h, ok := mux.handlers[muxKey{action, ""}]; ok {
+		return h
+	}
+	if _, ok := mux.actions[action]; ok {
+		return codeHandler(epp.ErrUnimplementedObject)
+	}
+	return codeHandler(epp.ErrUnimplementedCommand)
+}
+
+// HandleEPP dispatches cmd to the handler registered for its action and
+// object namespace.
+func (mux *ServeMux) HandleEPP(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+	return mux.Handler(cmd).HandleEPP(ctx, cmd)
+}
+
+// codeHandler is a [Handler] that responds with an EPP error code.
+type codeHandler epp.ResultCode
+
+func (h codeHandler) HandleEPP(context.Context, *epp.Command) (*epp.Response, error) {
+	return nil, epp.ResultCode(h)
+}
+
+// commandObject returns the object-specific child element of action a, or nil
+// if a has no object.
+func commandObject(a epp.Action) any {
+	switch a := a.(type) {
+	case *epp.Check:
+		return a.Check
+	case *epp.Create:
+		return a.Create
+	case *epp.Delete:
+		return a.Delete
+	case *epp.Info:
+		return a.Info
+	case *epp.Renew:
+		return a.Renew
+	case *epp.Transfer:
+		return a.Transfer
+	case *epp.Update:
+		return a.Update
+	}
+	return nil
+}
+
+// xmlName returns the XML name declared by the XMLName field of v,
+// stripping any namespace prefix from the local name.
+func xmlName(v any) xml.Name {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return xml.Name{}
+	}
+	f, ok := t.FieldByName("XMLName")
+	if !ok {
+		return xml.Name{}
+	}
+	tag, _, _ := strings.Cut(f.Tag.Get("xml"), ",")
+	space, local, ok := strings.Cut(tag, " ")
+	if !ok {
+		space, local = "", tag
+	}
+	if _, l, ok := strings.Cut(local, ":"); ok {
+		local = l
+	}
+	return xml.Name{Space: space, Local: local}
+}
@@ -0,0 +1,61 @@
+package epp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+type testCheck struct {
+	XMLName struct{} `xml:"urn:example:test-1.0 test:check"`
+}
+
+func (testCheck) EPPCheck() {}
+
+func TestServeMux(t *testing.T) {
+	mux := NewServeMux()
+	handler := func(code epp.ResultCode) HandlerFunc {
+		return func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, code), nil
+		}
+	}
+	mux.Handle("check", domain.NS, handler(epp.Success))
+	mux.Handle("poll", "", handler(epp.SuccessNoMessages))
+
+	tests := []struct {
+		name   string
+		action epp.Action
+		want   epp.ResultCode
+	}{
+		{`domain check`, &epp.Check{Check: &domain.Check{Names: []string{"example.com"}}}, epp.Success},
+		{`poll`, &epp.Poll{}, epp.SuccessNoMessages},
+		{`unimplemented object`, &epp.Check{Check: &testCheck{}}, epp.ErrUnimplementedObject},
+		{`unimplemented command`, &epp.Info{}, epp.ErrUnimplementedCommand},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &epp.Command{Action: tt.action}
+			res, err := mux.HandleEPP(context.Background(), cmd)
+			if err != nil {
+				res = errorResponse(&Config{}, err)
+			}
+			if got := res.Results[0].Code; got != tt.want {
+				t.Errorf("HandleEPP(): result code %04d, expected %04d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeMuxDuplicate(t *testing.T) {
+	mux := &ServeMux{}
+	h := HandlerFunc(func(context.Context, *epp.Command) (*epp.Response, error) { return nil, nil })
+	mux.Handle("check", domain.NS, h)
+	defer func() {
+		if recover() == nil {
+			t.Error("Handle(): expected panic for duplicate registration")
+		}
+	}()
+	mux.Handle("check", domain.NS, h)
+}
@@ -3,6 +3,7 @@ package epp
 import (
 	"context"
 	"crypto/tls"
+	"slices"
 	"time"
 
 	"github.com/domainr/epp2/internal/config"
@@ -23,18 +24,51 @@ func WithTLS(cfg *tls.Config) Options {
 	return (*config.TLSConfig)(cfg.Clone())
 }
 
+// LoadClientCertificate returns a copy of cfg with an additional client
+// certificate, read from a pair of PEM-encoded files, suitable for [WithTLS].
+// Servers may use the certificate to authenticate the client with mTLS.
+// If cfg is nil, a new [tls.Config] is returned.
+func LoadClientCertificate(cfg *tls.Config, certFile, keyFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = &tls.Config{}
+	} else {
+		cfg = cfg.Clone()
+	}
+	cfg.Certificates = append(slices.Clip(cfg.Certificates), cert)
+	return cfg, nil
+}
+
+// WithPipeline sets the maximum number of commands a client will send to a
+// server before receiving a response. The default depth of 1 disables
+// pipelining, which is required by servers that forbid it.
 func WithPipeline(depth int) Options {
 	return config.Pipeline(depth)
 }
 
+// WithBackoff sets the initial and maximum delay between reconnection attempts
+// made by a client returned from [DialReconnecting], or between attempts by a
+// [Pool] to open a session. The delay doubles after each failed attempt.
+func WithBackoff(initial, max time.Duration) Options {
+	return config.Backoff{Initial: initial, Max: max}
+}
+
 func WithContext(ctx context.Context) Options {
 	return config.Context{Context: ctx}
 }
 
+// WithKeepAlive sets the idle period after which a client will send an EPP
+// <hello> to the server to keep the session alive. It is also used as the TCP
+// keep-alive period for connections opened by [Dial] with the default dialer.
 func WithKeepAlive(d time.Duration) Options {
 	return config.KeepAlive(d)
 }
 
+// WithTimeout sets the maximum duration of each EPP command sent by a client,
+// including any time spent waiting for a slot in the pipeline.
 func WithTimeout(d time.Duration) Options {
 	return config.Timeout(d)
 }
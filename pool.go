@@ -0,0 +1,336 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/domainr/epp2/internal/config"
+	"github.com/domainr/epp2/protocol"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+// Pool maintains a pool of logged-in EPP sessions to a single server,
+// distributing commands across them. Sessions are opened with [Dial] and
+// logged in with ClientID and Password. Sessions that fail, or that receive a
+// result code indicating the server is closing the connection (2500–2599),
+// are replaced.
+//
+// If a session cannot be opened, for example because the server rejects the
+// login, further attempts are delayed with exponential backoff (see
+// [WithBackoff]) to avoid locking out the account. While waiting, commands
+// that have no session available return the most recent error.
+//
+// A Pool is safe to use from multiple goroutines. The zero value is not
+// usable; at minimum, Network and Addr must be set.
+type Pool struct {
+	// Network and Addr specify the server address passed to [Dial].
+	Network string
+	Addr    string
+
+	// Options are passed to [Dial] when opening a session.
+	Options []Options
+
+	// ClientID and Password are used to log in each session.
+	ClientID string
+	Password string
+
+	// Size is the number of sessions the Pool will try to maintain.
+	// If zero, 1 session will be used. Size is limited by MaxSessions.
+	Size int
+
+	// MaxSessions limits the number of sessions open or being opened at
+	// once. Registries typically limit the number of concurrent sessions
+	// per registrar. If MaxSessions is less than Size, the Pool maintains
+	// at most MaxSessions sessions. If zero, the limit is Size.
+	MaxSessions int
+
+	// HealthCheck is the interval between <hello> health checks of idle
+	// sessions. Failed sessions, including sessions closed after a command
+	// exceeded its timeout (see [WithTimeout]), are replaced. If zero,
+	// sessions are only checked when used.
+	HealthCheck time.Duration
+
+	startOnce  sync.Once
+	done       chan struct{}
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	mu       sync.Mutex
+	sessions []*client
+	opening  int
+	closed   bool
+	changed  chan struct{}
+
+	// failures counts consecutive failed attempts to open a session.
+	// No session is opened before retry. err is the most recent error.
+	failures int
+	retry    time.Time
+	err      error
+}
+
+var _ protocol.Client = &Pool{}
+
+// ExchangeEPP sends an EPP message over the least busy session in the pool
+// and returns the response. If no session is available, it opens a new
+// session, or waits for one if the pool is at MaxSessions. It blocks until a
+// response is received or the Context is canceled.
+func (p *Pool) ExchangeEPP(ctx context.Context, req epp.Body) (epp.Body, error) {
+	c, err := p.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.ExchangeEPP(ctx, req)
+	if err != nil {
+		// A session that exceeded the command timeout (see [WithTimeout])
+		// is closed, and is removed from the pool.
+		if c.closed() || (context.Cause(ctx) == nil && !errors.Is(err, context.DeadlineExceeded)) {
+			p.remove(c)
+		}
+		return nil, err
+	}
+	if res, ok := body.(*epp.Response); ok && isFatal(res) {
+		p.remove(c)
+	}
+	return body, nil
+}
+
+// Len returns the number of logged-in sessions in the pool.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.sessions)
+}
+
+// Shutdown gracefully closes each session in the pool, logging out after
+// in-flight commands complete. See [Client.Shutdown].
+func (p *Pool) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, c := range p.close() {
+		errs = append(errs, c.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Close immediately closes each session in the pool.
+func (p *Pool) Close() error {
+	var errs []error
+	for _, c := range p.close() {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (p *Pool) close() []*client {
+	p.start()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	sessions := p.sessions
+	p.sessions = nil
+	p.notify()
+	return sessions
+}
+
+func (p *Pool) start() {
+	p.startOnce.Do(func() {
+		p.done = make(chan struct{})
+		p.changed = make(chan struct{})
+		var cfg config.Config
+		cfg.Join(p.Options...)
+		p.backoff, p.maxBackoff = backoffDelays(&cfg)
+		if p.HealthCheck > 0 {
+			go p.healthCheck(p.HealthCheck)
+		}
+	})
+}
+
+// size returns the number of sessions to maintain.
+func (p *Pool) size() int {
+	n := max(p.Size, 1)
+	if p.MaxSessions > 0 {
+		n = min(n, p.MaxSessions)
+	}
+	return n
+}
+
+// maxSessions returns the limit on sessions open or being opened.
+func (p *Pool) maxSessions() int {
+	if p.MaxSessions > 0 {
+		return p.MaxSessions
+	}
+	return p.size()
+}
+
+// notify wakes goroutines waiting for a change to the pool.
+// p.mu must be held.
+func (p *Pool) notify() {
+	close(p.changed)
+	p.changed = make(chan struct{})
+}
+
+// get returns the least busy session, opening a new session if the pool has
+// fewer than Size sessions. If no session is available and the pool is
+// waiting to retry a failed open, get returns the error from the failed open.
+func (p *Pool) get(ctx context.Context) (*client, error) {
+	p.start()
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrClosedConnection
+		}
+		var best *client
+		for _, c := range p.sessions {
+			if c.closed() {
+				continue
+			}
+			if best == nil || c.InFlight() < best.InFlight() {
+				best = c
+			}
+		}
+		ready := !time.Now().Before(p.retry)
+		open := ready && (len(p.sessions)+p.opening < p.size() ||
+			(best == nil && len(p.sessions)+p.opening < p.maxSessions()))
+		if open {
+			p.opening++
+		}
+		if best == nil && !ready && p.opening == 0 {
+			err := p.err
+			p.mu.Unlock()
+			return nil, err
+		}
+		changed := p.changed
+		p.mu.Unlock()
+
+		switch {
+		case best != nil && open:
+			go p.open(context.Background())
+			return best, nil
+		case best != nil:
+			return best, nil
+		case open:
+			c, err := p.open(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return c, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		case <-changed:
+		}
+	}
+}
+
+// open dials and logs in a new session, adding it to the pool.
+// The caller must increment p.opening. If open fails, subsequent attempts are
+// delayed with exponential backoff.
+func (p *Pool) open(ctx context.Context) (*client, error) {
+	c, err := p.dial(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.opening--
+	p.notify()
+	if err != nil {
+		if context.Cause(ctx) == nil {
+			delay := p.backoff
+			for i := 0; i < p.failures && delay < p.maxBackoff; i++ {
+				delay *= 2
+			}
+			p.failures++
+			p.retry = time.Now().Add(min(delay, p.maxBackoff))
+			p.err = err
+		}
+		return nil, err
+	}
+	p.failures = 0
+	p.retry = time.Time{}
+	p.err = nil
+	if p.closed {
+		go c.Shutdown(context.Background())
+		return nil, ErrClosedConnection
+	}
+	p.sessions = append(p.sessions, c)
+	return c, nil
+}
+
+func (p *Pool) dial(ctx context.Context) (*client, error) {
+	opts := append(slices.Clone(p.Options), WithContext(ctx))
+	conn, err := Dial(p.Network, p.Addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := conn.(*client)
+	err = c.Login(ctx, p.ClientID, p.Password, nil)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// remove closes session c and removes it from the pool.
+func (p *Pool) remove(c *client) {
+	p.mu.Lock()
+	i := slices.Index(p.sessions, c)
+	if i >= 0 {
+		p.sessions = slices.Delete(p.sessions, i, i+1)
+		p.notify()
+	}
+	p.mu.Unlock()
+	c.Close()
+}
+
+// healthCheck sends a <hello> on each idle session every interval d,
+// replacing failed sessions, until the pool is closed.
+func (p *Pool) healthCheck(d time.Duration) {
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-t.C:
+		}
+
+		p.mu.Lock()
+		sessions := slices.Clone(p.sessions)
+		p.mu.Unlock()
+		for _, c := range sessions {
+			if c.closed() {
+				p.remove(c)
+				continue
+			}
+			if c.InFlight() > 0 {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), d)
+			_, err := c.Hello(ctx)
+			cancel()
+			if err != nil {
+				p.remove(c)
+			}
+		}
+
+		p.mu.Lock()
+		n := p.size() - len(p.sessions) - p.opening
+		if p.closed || time.Now().Before(p.retry) {
+			n = 0
+		}
+		p.opening += max(n, 0)
+		p.mu.Unlock()
+		for i := 0; i < n; i++ {
+			go p.open(context.Background())
+		}
+	}
+}
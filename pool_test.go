@@ -0,0 +1,279 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/domainr/epp2/schema/epp"
+)
+
+func TestPool(t *testing.T) {
+	release := make(chan struct{})
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		switch cmd.Action.(type) {
+		case *epp.Login:
+			return testResponse(cmd, epp.Success)
+		case *epp.Delete:
+			return testResponse(cmd, epp.ErrCommandFailedClosing)
+		}
+		<-release
+		return testResponse(cmd, epp.Success)
+	}
+
+	p := &Pool{
+		Network:  "tcp",
+		Addr:     "epp.example",
+		Options:  []Options{WithDialer(d)},
+		ClientID: "user",
+		Password: "password",
+		Size:     2,
+	}
+	defer p.Close()
+
+	ctx := context.Background()
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+			errs <- err
+		}()
+	}
+	for p.Len() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Each session should have one in-flight command.
+	for {
+		p.mu.Lock()
+		busy := 0
+		for _, c := range p.sessions {
+			busy += c.InFlight()
+		}
+		balanced := busy == 2 && p.sessions[0].InFlight() == 1
+		p.mu.Unlock()
+		if balanced {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("ExchangeEPP(): err == %v", err)
+		}
+	}
+
+	// A fatal result code removes the session from the pool.
+	_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Delete{}})
+	if err != nil {
+		t.Errorf("ExchangeEPP(): err == %v", err)
+	}
+	if n := p.Len(); n != 1 {
+		t.Errorf("Len() == %d, expected 1", n)
+	}
+}
+
+func TestPoolMaxSessions(t *testing.T) {
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		return testResponse(cmd, epp.Success)
+	}
+
+	p := &Pool{
+		Network:     "tcp",
+		Addr:        "epp.example",
+		Options:     []Options{WithDialer(d)},
+		ClientID:    "user",
+		Password:    "password",
+		Size:        5,
+		MaxSessions: 2,
+	}
+	defer p.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+	}
+	for p.Len() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 5; i++ {
+		_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+	}
+
+	// The pool does not open more than MaxSessions sessions.
+	time.Sleep(20 * time.Millisecond)
+	if n := d.dials.Load(); n != 2 {
+		t.Errorf("dials == %d, expected 2", n)
+	}
+	if n := p.Len(); n != 2 {
+		t.Errorf("Len() == %d, expected 2", n)
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		return testResponse(cmd, epp.Success)
+	}
+
+	p := &Pool{
+		Network:     "tcp",
+		Addr:        "epp.example",
+		Options:     []Options{WithDialer(d)},
+		ClientID:    "user",
+		Password:    "password",
+		HealthCheck: 10 * time.Millisecond,
+	}
+	defer p.Close()
+
+	ctx := context.Background()
+	_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+	if err != nil {
+		t.Fatalf("ExchangeEPP(): err == %v", err)
+	}
+	p.mu.Lock()
+	c := p.sessions[0]
+	p.mu.Unlock()
+
+	// The health check replaces a session whose connection has failed.
+	c.conn.Close()
+	deadline := time.Now().Add(time.Second)
+	for {
+		p.mu.Lock()
+		replaced := len(p.sessions) == 1 && p.sessions[0] != c
+		p.mu.Unlock()
+		if replaced {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("health check did not replace the failed session")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := d.dials.Load(); n != 2 {
+		t.Errorf("dials == %d, expected 2", n)
+	}
+	if !c.closed() {
+		t.Error("failed session was not closed")
+	}
+}
+
+func TestPoolBackoff(t *testing.T) {
+	var logins atomic.Int32
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		if _, ok := cmd.Action.(*epp.Login); ok {
+			logins.Add(1)
+			if n > 1 {
+				return testResponse(cmd, epp.ErrAuthentication)
+			}
+		}
+		return testResponse(cmd, epp.Success)
+	}
+
+	p := &Pool{
+		Network:  "tcp",
+		Addr:     "epp.example",
+		Options:  []Options{WithDialer(d), WithBackoff(time.Hour, time.Hour)},
+		ClientID: "user",
+		Password: "password",
+		Size:     2,
+	}
+	defer p.Close()
+
+	// The first command opens a session, and starts opening a second
+	// session in the background, which fails.
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+	}
+	for {
+		p.mu.Lock()
+		failed := p.failures > 0
+		p.mu.Unlock()
+		if failed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Further commands do not attempt to open a session until the backoff
+	// delay elapses.
+	for i := 0; i < 3; i++ {
+		_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		if err != nil {
+			t.Errorf("ExchangeEPP(): err == %v", err)
+		}
+	}
+	if n := logins.Load(); n != 2 {
+		t.Errorf("logins == %d, expected 2", n)
+	}
+
+	// Without a session, the error from the failed open is returned.
+	p.mu.Lock()
+	c := p.sessions[0]
+	p.mu.Unlock()
+	p.remove(c)
+	_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+	var r *epp.Result
+	if !errors.As(err, &r) || r.Code != epp.ErrAuthentication {
+		t.Errorf("ExchangeEPP(): err == %v, expected %04d", err, epp.ErrAuthentication)
+	}
+	if n := logins.Load(); n != 2 {
+		t.Errorf("logins == %d, expected 2", n)
+	}
+}
+
+func TestPoolTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		if _, ok := cmd.Action.(*epp.Info); ok {
+			<-release
+		}
+		return testResponse(cmd, epp.Success)
+	}
+
+	p := &Pool{
+		Network:  "tcp",
+		Addr:     "epp.example",
+		Options:  []Options{WithDialer(d), WithTimeout(20 * time.Millisecond)},
+		ClientID: "user",
+		Password: "password",
+	}
+	defer p.Close()
+
+	// A session that exceeds the command timeout is removed from the pool.
+	ctx := context.Background()
+	_, err := p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Info{}})
+	if err != context.DeadlineExceeded {
+		t.Errorf("ExchangeEPP(): err == %v, expected %v", err, context.DeadlineExceeded)
+	}
+	if n := p.Len(); n != 0 {
+		t.Errorf("Len() == %d, expected 0", n)
+	}
+
+	// The next command uses a new session.
+	_, err = p.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+	if err != nil {
+		t.Errorf("ExchangeEPP(): err == %v", err)
+	}
+	if n := d.dials.Load(); n != 2 {
+		t.Errorf("dials == %d, expected 2", n)
+	}
+}
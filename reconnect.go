@@ -0,0 +1,330 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/domainr/epp2/internal/config"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+const (
+	defaultBackoff    = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+// DialReconnecting connects to an EPP server like [Dial], returning a [Client]
+// that transparently reconnects if the connection fails.
+//
+// Reconnection attempts are delayed with exponential backoff (see
+// [WithBackoff]). After a successful Login, each new connection is logged in
+// with the same credentials and the services negotiated for the first
+// session.
+//
+// Commands not yet sent when a connection fails are submitted on a new
+// connection. Idempotent commands (<check>, <info>, <poll op="req">, and
+// <transfer op="query">) interrupted by a connection failure are resubmitted
+// on a new connection. Other interrupted commands return an [AmbiguousError],
+// as the server may have executed them. Errors detected before a command is
+// sent, such as a [DuplicateTransactionIDError], are returned unchanged and do
+// not affect the connection.
+func DialReconnecting(network, addr string, opts ...Options) (Client, error) {
+	var cfg config.Config
+	cfg.Join(opts...)
+	backoff, maxBackoff := backoffDelays(&cfg)
+	r := &reconnectingClient{
+		network:    network,
+		addr:       addr,
+		opts:       opts,
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		dialing:    make(chan struct{}, 1),
+	}
+	c, err := r.dial(cfg.Context)
+	if err != nil {
+		return nil, err
+	}
+	r.client = c
+	return r, nil
+}
+
+type reconnectingClient struct {
+	network    string
+	addr       string
+	opts       []Options
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	// dialing serializes reconnection attempts.
+	dialing chan struct{}
+
+	mu       sync.Mutex
+	client   *client
+	closed   bool
+	session  *Config
+	clientID string
+	password string
+}
+
+func (r *reconnectingClient) ExchangeEPP(ctx context.Context, req epp.Body) (epp.Body, error) {
+	for {
+		c, err := r.current(ctx)
+		if err != nil {
+			return nil, err
+		}
+		body, sent, err := c.transact(ctx, req)
+		if err == nil {
+			if res, ok := body.(*epp.Response); ok && isFatal(res) {
+				r.drop(c)
+			}
+			return body, nil
+		}
+		if !sent {
+			if err != ErrClosedConnection {
+				// The command was rejected before it was sent, e.g. with
+				// a duplicate transaction ID or a canceled Context.
+				return nil, err
+			}
+			// The connection closed before req was sent, so it is safe
+			// to resubmit req on a new connection.
+			r.drop(c)
+			if r.isClosed() {
+				return nil, ErrClosedConnection
+			}
+			continue
+		}
+		if context.Cause(ctx) != nil || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		r.drop(c)
+		if r.isClosed() {
+			return nil, ErrClosedConnection
+		}
+		if !isIdempotent(req) {
+			return nil, AmbiguousError{Err: err}
+		}
+	}
+}
+
+func (r *reconnectingClient) Hello(ctx context.Context) (*epp.Greeting, error) {
+	c, err := r.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.Hello(ctx)
+}
+
+func (r *reconnectingClient) InFlight() int {
+	r.mu.Lock()
+	c := r.client
+	r.mu.Unlock()
+	if c == nil {
+		return 0
+	}
+	return c.InFlight()
+}
+
+func (r *reconnectingClient) Login(ctx context.Context, clientID, password string, newPassword *string) error {
+	c, err := r.current(ctx)
+	if err != nil {
+		return err
+	}
+	cfg, err := c.negotiate()
+	if err != nil {
+		return err
+	}
+	err = c.login(ctx, cfg, clientID, password, newPassword)
+	if err != nil {
+		return err
+	}
+	if newPassword != nil {
+		password = *newPassword
+	}
+	r.mu.Lock()
+	r.session = cfg
+	r.clientID = clientID
+	r.password = password
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *reconnectingClient) Logout(ctx context.Context) error {
+	c := r.close()
+	if c == nil {
+		return ErrClosedConnection
+	}
+	return c.Logout(ctx)
+}
+
+func (r *reconnectingClient) CheckDomains(ctx context.Context, names ...string) (map[string]DomainCheck, error) {
+	return checkDomains(ctx, r, names)
+}
+
+func (r *reconnectingClient) Shutdown(ctx context.Context) error {
+	c := r.close()
+	if c == nil {
+		return nil
+	}
+	return c.Shutdown(ctx)
+}
+
+func (r *reconnectingClient) Close() error {
+	c := r.close()
+	if c == nil {
+		return nil
+	}
+	return c.Close()
+}
+
+// close stops reconnection attempts and returns the current connection, if any.
+func (r *reconnectingClient) close() *client {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	c := r.client
+	r.client = nil
+	return c
+}
+
+func (r *reconnectingClient) isClosed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+// drop closes and discards connection c if it is the current connection.
+func (r *reconnectingClient) drop(c *client) {
+	r.mu.Lock()
+	if r.client == c {
+		r.client = nil
+	}
+	r.mu.Unlock()
+	c.Close()
+}
+
+// current returns the current connection, reconnecting with backoff if
+// necessary. It blocks until connected, the client is closed, or ctx is
+// canceled.
+func (r *reconnectingClient) current(ctx context.Context) (*client, error) {
+	r.mu.Lock()
+	c, closed := r.client, r.closed
+	r.mu.Unlock()
+	if closed {
+		return nil, ErrClosedConnection
+	}
+	if c != nil {
+		return c, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	case r.dialing <- struct{}{}:
+	}
+	defer func() { <-r.dialing }()
+
+	for delay := r.backoff; ; delay = min(delay*2, r.maxBackoff) {
+		r.mu.Lock()
+		c, closed := r.client, r.closed
+		r.mu.Unlock()
+		if closed {
+			return nil, ErrClosedConnection
+		}
+		if c != nil {
+			return c, nil
+		}
+
+		c, err := r.dial(ctx)
+		if err == nil {
+			r.mu.Lock()
+			r.client = c
+			r.mu.Unlock()
+			continue
+		}
+		var result *epp.Result
+		if errors.As(err, &result) || errors.As(err, &NegotiationError{}) {
+			// Retrying will not fix a rejected login.
+			return nil, err
+		}
+
+		t := time.NewTimer(rand.N(delay))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, context.Cause(ctx)
+		case <-t.C:
+		}
+	}
+}
+
+// dial opens a new connection, logging in if a previous connection was
+// logged in.
+func (r *reconnectingClient) dial(ctx context.Context) (*client, error) {
+	opts := slices.Clone(r.opts)
+	if ctx != nil {
+		opts = append(opts, WithContext(ctx))
+	}
+	conn, err := Dial(r.network, r.addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := conn.(*client)
+
+	r.mu.Lock()
+	session, clientID, password := r.session, r.clientID, r.password
+	r.mu.Unlock()
+	if session != nil {
+		err = c.login(ctx, session, clientID, password, nil)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// backoffDelays returns the initial and maximum delay between connection
+// attempts configured by [WithBackoff], or the defaults.
+func backoffDelays(cfg *config.Config) (initial, maximum time.Duration) {
+	initial, maximum = cfg.Backoff, cfg.MaxBackoff
+	if initial <= 0 {
+		initial = defaultBackoff
+	}
+	if maximum < initial {
+		maximum = max(initial, defaultMaxBackoff)
+	}
+	return initial, maximum
+}
+
+// isIdempotent returns true if req can be safely resubmitted to a server.
+func isIdempotent(req epp.Body) bool {
+	switch req := req.(type) {
+	case *epp.Hello:
+		return true
+	case *epp.Command:
+		switch a := req.Action.(type) {
+		case *epp.Check, *epp.Info:
+			return true
+		case *epp.Poll:
+			return a.Op == epp.PollRequest
+		case *epp.Transfer:
+			return a.Op == epp.TransferQuery
+		}
+	}
+	return false
+}
+
+// isFatal returns true if res contains a result code indicating the server is
+// closing the connection.
+func isFatal(res *epp.Response) bool {
+	for _, r := range res.Results {
+		if r.Code.IsFatal() {
+			return true
+		}
+	}
+	return false
+}
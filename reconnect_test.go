@@ -0,0 +1,158 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/domainr/epp2/schema/epp"
+)
+
+// pipeDialer implements [ContextDialer], serving each new connection with
+// a test server that calls f with the connection index.
+type pipeDialer struct {
+	t     *testing.T
+	dials atomic.Int32
+	f     func(n int32, cmd *epp.Command) *epp.Response
+}
+
+func (d *pipeDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	n := d.dials.Add(1)
+	clientConn, serverConn := net.Pipe()
+	go testServer(d.t, serverConn, testGreeting, func(cmd *epp.Command) *epp.Response {
+		res := d.f(n, cmd)
+		if res == nil {
+			serverConn.Close()
+		}
+		return res
+	})
+	return clientConn, nil
+}
+
+func TestReconnectingClient(t *testing.T) {
+	var logins atomic.Int32
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		switch a := cmd.Action.(type) {
+		case *epp.Login:
+			if a.ClientID != "user" || a.Password != "password" {
+				return testResponse(cmd, epp.ErrAuthentication)
+			}
+			logins.Add(1)
+			return testResponse(cmd, epp.Success)
+		case *epp.Check, *epp.Create:
+			// Drop the first connection.
+			if n == 1 {
+				return nil
+			}
+		}
+		return testResponse(cmd, epp.Success)
+	}
+
+	c, err := DialReconnecting("tcp", "epp.example", WithDialer(d), WithBackoff(time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("DialReconnecting(): err == %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	err = c.Login(ctx, "user", "password", nil)
+	if err != nil {
+		t.Fatalf("Login(): err == %v", err)
+	}
+
+	// An idempotent command is resubmitted on a new connection.
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Check{}})
+	if err != nil {
+		t.Errorf("ExchangeEPP(<check>): err == %v", err)
+	}
+	if n := d.dials.Load(); n != 2 {
+		t.Errorf("dials == %d, expected 2", n)
+	}
+	if n := logins.Load(); n != 2 {
+		t.Errorf("logins == %d, expected 2", n)
+	}
+}
+
+func TestReconnectingClientUnsent(t *testing.T) {
+	release := make(chan struct{})
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		if _, ok := cmd.Action.(*epp.Info); ok {
+			<-release
+		}
+		return testResponse(cmd, epp.Success)
+	}
+
+	c, err := DialReconnecting("tcp", "epp.example", WithDialer(d), WithPipeline(2), WithBackoff(time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("DialReconnecting(): err == %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Info{}, ClientTransactionID: "dup"})
+		errs <- err
+	}()
+	for c.InFlight() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A command rejected before it is sent returns its error unchanged, and
+	// does not affect the connection or in-flight commands.
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Create{}, ClientTransactionID: "dup"})
+	if err != (DuplicateTransactionIDError{TransactionID: "dup"}) {
+		t.Errorf("ExchangeEPP(<create>): err == %v, expected DuplicateTransactionIDError", err)
+	}
+	close(release)
+	if err := <-errs; err != nil {
+		t.Errorf("ExchangeEPP(<info>): err == %v", err)
+	}
+
+	// A non-idempotent command is submitted on a new connection if the
+	// connection closed before it was sent.
+	c.(*reconnectingClient).client.Close()
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Create{}})
+	if err != nil {
+		t.Errorf("ExchangeEPP(<create>): err == %v", err)
+	}
+	if n := d.dials.Load(); n != 2 {
+		t.Errorf("dials == %d, expected 2", n)
+	}
+}
+
+func TestReconnectingClientAmbiguous(t *testing.T) {
+	d := &pipeDialer{t: t}
+	d.f = func(n int32, cmd *epp.Command) *epp.Response {
+		if _, ok := cmd.Action.(*epp.Create); ok && n == 1 {
+			return nil
+		}
+		return testResponse(cmd, epp.Success)
+	}
+
+	c, err := DialReconnecting("tcp", "epp.example", WithDialer(d), WithBackoff(time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("DialReconnecting(): err == %v", err)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Create{}})
+	if !errors.As(err, &AmbiguousError{}) {
+		t.Errorf("ExchangeEPP(<create>): err == %v, expected AmbiguousError", err)
+	}
+
+	// The next command uses a new connection.
+	_, err = c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Create{}})
+	if err != nil {
+		t.Errorf("ExchangeEPP(<create>): err == %v", err)
+	}
+	if n := d.dials.Load(); n != 2 {
+		t.Errorf("dials == %d, expected 2", n)
+	}
+}
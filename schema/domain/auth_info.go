@@ -0,0 +1,14 @@
+package domain
+
+// AuthInfo represents a <domain:authInfo> element, containing authorization
+// information associated with a domain object.
+type AuthInfo struct {
+	Password Password `xml:"domain:pw"`
+}
+
+// Password represents a <domain:pw> element. ROID optionally identifies the
+// registrant or contact object the password is associated with.
+type Password struct {
+	ROID     string `xml:"roid,attr,omitempty"`
+	Password string `xml:",chardata"`
+}
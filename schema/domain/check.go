@@ -1,5 +1,7 @@
 package domain
 
+import "github.com/domainr/epp2/schema/std"
+
 // Check represents an EPP <domain:check> command.
 // See https://www.rfc-editor.org/rfc/rfc5730.html.
 type Check struct {
@@ -8,3 +10,33 @@ type Check struct {
 }
 
 func (Check) EPPCheck() {}
+
+// CheckData represents an EPP <domain:chkData> response.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.1.1.
+type CheckData struct {
+	XMLName struct{}      `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:chkData"`
+	Results []CheckResult `xml:"domain:cd,omitempty"`
+}
+
+func (CheckData) EPPResponseData() {}
+
+// CheckResult represents a <domain:cd> element, describing the availability
+// of a single domain name.
+type CheckResult struct {
+	Name   CheckName `xml:"domain:name"`
+	Reason *Reason   `xml:"domain:reason,omitempty"`
+}
+
+// CheckName represents the <domain:name> element of a <domain:cd> element.
+// Available reports whether the domain object can be provisioned.
+type CheckName struct {
+	Available std.Bool `xml:"avail,attr"`
+	Name      string   `xml:",chardata"`
+}
+
+// Reason represents a <domain:reason> element, a human-readable explanation
+// in language Lang.
+type Reason struct {
+	Lang   string `xml:"lang,attr,omitempty"`
+	Reason string `xml:",chardata"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+// Contact represents a <domain:contact> element, associating a contact object
+// with a domain object.
+type Contact struct {
+	Type ContactType `xml:"type,attr"`
+	ID   string      `xml:",chardata"`
+}
+
+// ContactType represents the type attribute of a <domain:contact> element.
+type ContactType string
+
+// Contact types defined in RFC 5731.
+const (
+	ContactAdmin   ContactType = "admin"
+	ContactBilling ContactType = "billing"
+	ContactTech    ContactType = "tech"
+)
@@ -0,0 +1,28 @@
+package domain
+
+import "github.com/domainr/epp2/schema/std"
+
+// Create represents an EPP <domain:create> command.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.2.1.
+type Create struct {
+	XMLName     struct{}     `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:create"`
+	Name        string       `xml:"domain:name"`
+	Period      *Period      `xml:"domain:period,omitempty"`
+	Nameservers *Nameservers `xml:"domain:ns,omitempty"`
+	Registrant  string       `xml:"domain:registrant,omitempty"`
+	Contacts    []Contact    `xml:"domain:contact,omitempty"`
+	AuthInfo    AuthInfo     `xml:"domain:authInfo"`
+}
+
+func (Create) EPPCreate() {}
+
+// CreateData represents an EPP <domain:creData> response.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.2.1.
+type CreateData struct {
+	XMLName    struct{}  `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:creData"`
+	Name       string    `xml:"domain:name"`
+	CreateDate *std.Time `xml:"domain:crDate,omitempty"`
+	ExpireDate *std.Time `xml:"domain:exDate,omitempty"`
+}
+
+func (CreateData) EPPResponseData() {}
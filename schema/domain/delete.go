@@ -0,0 +1,10 @@
+package domain
+
+// Delete represents an EPP <domain:delete> command.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.2.2.
+type Delete struct {
+	XMLName struct{} `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:delete"`
+	Name    string   `xml:"domain:name"`
+}
+
+func (Delete) EPPDelete() {}
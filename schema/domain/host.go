@@ -0,0 +1,31 @@
+package domain
+
+// Nameservers represents a <domain:ns> element. A server supports either
+// host objects or host attributes, but not both.
+type Nameservers struct {
+	HostObjects    []string        `xml:"domain:hostObj,omitempty"`
+	HostAttributes []HostAttribute `xml:"domain:hostAttr,omitempty"`
+}
+
+// HostAttribute represents a <domain:hostAttr> element, describing a name
+// server as an attribute of a domain object rather than as a host object.
+type HostAttribute struct {
+	Name      string        `xml:"domain:hostName"`
+	Addresses []HostAddress `xml:"domain:hostAddr,omitempty"`
+}
+
+// HostAddress represents a <domain:hostAddr> element. IP is either IPv4 or
+// IPv6; if empty, the server assumes IPv4.
+type HostAddress struct {
+	IP      IPVersion `xml:"ip,attr,omitempty"`
+	Address string    `xml:",chardata"`
+}
+
+// IPVersion represents the ip attribute of a <domain:hostAddr> element.
+type IPVersion string
+
+// IP address versions.
+const (
+	IPv4 IPVersion = "v4"
+	IPv6 IPVersion = "v6"
+)
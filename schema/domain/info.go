@@ -0,0 +1,55 @@
+package domain
+
+import "github.com/domainr/epp2/schema/std"
+
+// Info represents an EPP <domain:info> command.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.1.2.
+type Info struct {
+	XMLName  struct{}  `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:info"`
+	Name     InfoName  `xml:"domain:name"`
+	AuthInfo *AuthInfo `xml:"domain:authInfo,omitempty"`
+}
+
+func (Info) EPPInfo() {}
+
+// InfoName represents the <domain:name> element of a <domain:info> command.
+// Hosts optionally controls which host information is returned.
+type InfoName struct {
+	Hosts Hosts  `xml:"hosts,attr,omitempty"`
+	Name  string `xml:",chardata"`
+}
+
+// Hosts represents the hosts attribute of a <domain:info> command.
+type Hosts string
+
+// Values for the hosts attribute of a <domain:info> command. The server
+// default is HostsAll.
+const (
+	HostsAll         Hosts = "all"
+	HostsDelegated   Hosts = "del"
+	HostsSubordinate Hosts = "sub"
+	HostsNone        Hosts = "none"
+)
+
+// InfoData represents an EPP <domain:infData> response.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.1.2.
+type InfoData struct {
+	XMLName      struct{}     `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:infData"`
+	Name         string       `xml:"domain:name"`
+	ROID         string       `xml:"domain:roid"`
+	Statuses     []Status     `xml:"domain:status,omitempty"`
+	Registrant   string       `xml:"domain:registrant,omitempty"`
+	Contacts     []Contact    `xml:"domain:contact,omitempty"`
+	Nameservers  *Nameservers `xml:"domain:ns,omitempty"`
+	Hosts        []string     `xml:"domain:host,omitempty"`
+	ClientID     string       `xml:"domain:clID"`
+	CreatorID    string       `xml:"domain:crID,omitempty"`
+	CreateDate   *std.Time    `xml:"domain:crDate,omitempty"`
+	UpdaterID    string       `xml:"domain:upID,omitempty"`
+	UpdateDate   *std.Time    `xml:"domain:upDate,omitempty"`
+	ExpireDate   *std.Time    `xml:"domain:exDate,omitempty"`
+	TransferDate *std.Time    `xml:"domain:trDate,omitempty"`
+	AuthInfo     *AuthInfo    `xml:"domain:authInfo,omitempty"`
+}
+
+func (InfoData) EPPResponseData() {}
@@ -0,0 +1,27 @@
+package domain
+
+// Period represents a <domain:period> element, the registration period of a
+// domain object. Servers may restrict the allowed periods.
+type Period struct {
+	Unit  PeriodUnit `xml:"unit,attr"`
+	Value int        `xml:",chardata"`
+}
+
+// Years returns a Period of n years.
+func Years(n int) *Period {
+	return &Period{Unit: PeriodYears, Value: n}
+}
+
+// Months returns a Period of n months.
+func Months(n int) *Period {
+	return &Period{Unit: PeriodMonths, Value: n}
+}
+
+// PeriodUnit represents the unit attribute of a <domain:period> element.
+type PeriodUnit string
+
+// Period units defined in RFC 5731.
+const (
+	PeriodYears  PeriodUnit = "y"
+	PeriodMonths PeriodUnit = "m"
+)
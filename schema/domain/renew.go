@@ -0,0 +1,25 @@
+package domain
+
+import "github.com/domainr/epp2/schema/std"
+
+// Renew represents an EPP <domain:renew> command. CurrentExpireDate must match
+// the current expiration date of the domain object.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.2.3.
+type Renew struct {
+	XMLName           struct{} `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:renew"`
+	Name              string   `xml:"domain:name"`
+	CurrentExpireDate std.Date `xml:"domain:curExpDate"`
+	Period            *Period  `xml:"domain:period,omitempty"`
+}
+
+func (Renew) EPPRenew() {}
+
+// RenewData represents an EPP <domain:renData> response.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.2.3.
+type RenewData struct {
+	XMLName    struct{}  `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:renData"`
+	Name       string    `xml:"domain:name"`
+	ExpireDate *std.Time `xml:"domain:exDate,omitempty"`
+}
+
+func (RenewData) EPPResponseData() {}
@@ -30,9 +30,30 @@ func (schemaString) ResolveXML(name xml.Name) any {
 		return nil
 	}
 	switch name.Local {
-	// TODO: other types.
 	case "check":
 		return &Check{}
+	case "chkData":
+		return &CheckData{}
+	case "create":
+		return &Create{}
+	case "creData":
+		return &CreateData{}
+	case "delete":
+		return &Delete{}
+	case "info":
+		return &Info{}
+	case "infData":
+		return &InfoData{}
+	case "renew":
+		return &Renew{}
+	case "renData":
+		return &RenewData{}
+	case "transfer":
+		return &Transfer{}
+	case "trnData":
+		return &TransferData{}
+	case "update":
+		return &Update{}
 	}
 	return nil
 }
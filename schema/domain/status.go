@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/domainr/epp2/internal/xml"
+	"github.com/domainr/epp2/status"
+)
+
+// Status represents a <domain:status> element. Status must be a single
+// [status.Code]. An unrecognized status value decodes as [status.Unknown].
+// Reason is optional human-readable text describing the status, in language
+// Lang.
+type Status struct {
+	Status status.Code `xml:"s,attr"`
+	Lang   string      `xml:"lang,attr,omitempty"`
+	Reason string      `xml:",chardata"`
+}
+
+// statusElement is the XML representation of a Status.
+type statusElement struct {
+	Status statusCode `xml:"s,attr"`
+	Lang   string     `xml:"lang,attr,omitempty"`
+	Reason string     `xml:",chardata"`
+}
+
+// MarshalXML implements the [xml.Marshaler] interface.
+func (s Status) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(statusElement{statusCode(s.Status), s.Lang, s.Reason}, start)
+}
+
+// UnmarshalXML implements the [xml.Unmarshaler] interface.
+func (s *Status) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v statusElement
+	err := d.DecodeElement(&v, &start)
+	if err != nil {
+		return err
+	}
+	*s = Status{status.Code(v.Status), v.Lang, v.Reason}
+	return nil
+}
+
+// statusCode is a single [status.Code], represented in XML by its EPP name,
+// e.g. "clientHold".
+type statusCode status.Code
+
+// MarshalXMLAttr implements the [xml.MarshalerAttr] interface. It returns an
+// error unless c is exactly one status code.
+func (c statusCode) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	s, ok := statusNames[status.Code(c)]
+	if !ok {
+		return xml.Attr{}, fmt.Errorf("domain: cannot marshal status %#x", uint32(c))
+	}
+	return xml.Attr{Name: name, Value: s}, nil
+}
+
+// UnmarshalXMLAttr implements the [xml.UnmarshalerAttr] interface.
+// Unrecognized values decode as [status.Unknown].
+func (c *statusCode) UnmarshalXMLAttr(attr xml.Attr) error {
+	*c = statusCode(status.Parse(attr.Value))
+	return nil
+}
+
+// statusNames maps each single status.Code to its EPP name.
+var statusNames = map[status.Code]string{
+	status.OK:                       "ok",
+	status.Linked:                   "linked",
+	status.AddPeriod:                "addPeriod",
+	status.AutoRenewPeriod:          "autoRenewPeriod",
+	status.Inactive:                 "inactive",
+	status.PendingCreate:            "pendingCreate",
+	status.PendingDelete:            "pendingDelete",
+	status.PendingRenew:             "pendingRenew",
+	status.PendingRestore:           "pendingRestore",
+	status.PendingTransfer:          "pendingTransfer",
+	status.PendingUpdate:            "pendingUpdate",
+	status.RedemptionPeriod:         "redemptionPeriod",
+	status.RenewPeriod:              "renewPeriod",
+	status.ServerDeleteProhibited:   "serverDeleteProhibited",
+	status.ServerHold:               "serverHold",
+	status.ServerRenewProhibited:    "serverRenewProhibited",
+	status.ServerTransferProhibited: "serverTransferProhibited",
+	status.ServerUpdateProhibited:   "serverUpdateProhibited",
+	status.TransferPeriod:           "transferPeriod",
+	status.ClientDeleteProhibited:   "clientDeleteProhibited",
+	status.ClientHold:               "clientHold",
+	status.ClientRenewProhibited:    "clientRenewProhibited",
+	status.ClientTransferProhibited: "clientTransferProhibited",
+	status.ClientUpdateProhibited:   "clientUpdateProhibited",
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/internal/xml"
+	"github.com/domainr/epp2/schema/schematest"
+	"github.com/domainr/epp2/status"
+)
+
+func TestStatus(t *testing.T) {
+	type T struct {
+		XMLName  struct{} `xml:"example"`
+		Statuses []Status `xml:"status"`
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			`single status`,
+			&T{Statuses: []Status{{Status: status.OK}}},
+			`<example><status s="ok"></status></example>`,
+		},
+		{
+			`status with reason`,
+			&T{Statuses: []Status{
+				{Status: status.ClientHold, Lang: "en", Reason: "Payment overdue."},
+				{Status: status.ServerTransferProhibited},
+			}},
+			`<example><status s="clientHold" lang="en">Payment overdue.</status><status s="serverTransferProhibited"></status></example>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, nil, tt.v, tt.want, false)
+		})
+	}
+}
+
+func TestStatusMarshalInvalid(t *testing.T) {
+	for _, c := range []status.Code{status.Unknown, status.ClientHold | status.ServerHold} {
+		_, err := xml.Marshal(&Status{Status: c})
+		if err == nil {
+			t.Errorf("xml.Marshal(Status{%#x}): err == nil, expected error", uint32(c))
+		}
+	}
+}
+
+func TestStatusUnmarshalUnknown(t *testing.T) {
+	var s Status
+	err := xml.Unmarshal([]byte(`<status s="bogus">Registry status.</status>`), &s)
+	if err != nil {
+		t.Fatalf("xml.Unmarshal(): err == %v", err)
+	}
+	want := Status{Status: status.Unknown, Reason: "Registry status."}
+	if s != want {
+		t.Errorf("xml.Unmarshal() == %+v, expected %+v", s, want)
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "github.com/domainr/epp2/schema/std"
+
+// Transfer represents an EPP <domain:transfer> command. The transfer operation
+// is specified by the enclosing epp.Transfer. Period is only used with a
+// transfer request.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.2.4.
+type Transfer struct {
+	XMLName  struct{}  `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:transfer"`
+	Name     string    `xml:"domain:name"`
+	Period   *Period   `xml:"domain:period,omitempty"`
+	AuthInfo *AuthInfo `xml:"domain:authInfo,omitempty"`
+}
+
+func (Transfer) EPPTransfer() {}
+
+// TransferData represents an EPP <domain:trnData> response.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.1.3.
+type TransferData struct {
+	XMLName      struct{}       `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:trnData"`
+	Name         string         `xml:"domain:name"`
+	Status       TransferStatus `xml:"domain:trStatus"`
+	RequestingID string         `xml:"domain:reID"`
+	RequestDate  *std.Time      `xml:"domain:reDate,omitempty"`
+	ActingID     string         `xml:"domain:acID"`
+	ActionDate   *std.Time      `xml:"domain:acDate,omitempty"`
+	ExpireDate   *std.Time      `xml:"domain:exDate,omitempty"`
+}
+
+func (TransferData) EPPResponseData() {}
+
+// TransferStatus represents the state of a transfer request, as defined in
+// RFC 5730.
+type TransferStatus string
+
+// Transfer statuses.
+const (
+	TransferClientApproved  TransferStatus = "clientApproved"
+	TransferClientCancelled TransferStatus = "clientCancelled"
+	TransferClientRejected  TransferStatus = "clientRejected"
+	TransferPending         TransferStatus = "pending"
+	TransferServerApproved  TransferStatus = "serverApproved"
+	TransferServerCancelled TransferStatus = "serverCancelled"
+)
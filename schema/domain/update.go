@@ -0,0 +1,28 @@
+package domain
+
+// Update represents an EPP <domain:update> command.
+// See https://www.rfc-editor.org/rfc/rfc5731.html#section-3.2.5.
+type Update struct {
+	XMLName struct{}      `xml:"urn:ietf:params:xml:ns:domain-1.0 domain:update"`
+	Name    string        `xml:"domain:name"`
+	Add     *UpdateValues `xml:"domain:add,omitempty"`
+	Remove  *UpdateValues `xml:"domain:rem,omitempty"`
+	Change  *UpdateChange `xml:"domain:chg,omitempty"`
+}
+
+func (Update) EPPUpdate() {}
+
+// UpdateValues represents a <domain:add> or <domain:rem> element, containing
+// values to add to or remove from a domain object.
+type UpdateValues struct {
+	Nameservers *Nameservers `xml:"domain:ns,omitempty"`
+	Contacts    []Contact    `xml:"domain:contact,omitempty"`
+	Statuses    []Status     `xml:"domain:status,omitempty"`
+}
+
+// UpdateChange represents a <domain:chg> element, containing values to change
+// on a domain object. An empty, non-nil Registrant removes the registrant.
+type UpdateChange struct {
+	Registrant *string   `xml:"domain:registrant,omitempty"`
+	AuthInfo   *AuthInfo `xml:"domain:authInfo,omitempty"`
+}
@@ -45,6 +45,35 @@ func TestCheckRoundTrip(t *testing.T) {
 			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><check><domain:check xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:check></check></command></epp>`,
 			false,
 		},
+		{
+			`<domain:chkData> response`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Response{
+					Results: []epp.Result{
+						{
+							Code:    epp.Success,
+							Message: epp.Success.Message(),
+						},
+					},
+					Data: []epp.ResponseData{
+						&domain.CheckData{
+							Results: []domain.CheckResult{
+								{
+									Name: domain.CheckName{Available: true, Name: "example.com"},
+								},
+								{
+									Name:   domain.CheckName{Available: false, Name: "example.net"},
+									Reason: &domain.Reason{Lang: "en", Reason: "In use"},
+								},
+							},
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="1000"><msg lang="en">Command completed successfully</msg></result><resData><domain:chkData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:cd><domain:name avail="1">example.com</domain:name></domain:cd><domain:cd><domain:name avail="0">example.net</domain:name><domain:reason lang="en">In use</domain:reason></domain:cd></domain:chkData></resData><trID><clTRID></clTRID><svTRID></svTRID></trID></response></epp>`,
+			false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -90,6 +90,26 @@ func TestCommandRoundTrip(t *testing.T) {
 			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><poll></poll></command></epp>`,
 			false,
 		},
+		{
+			`<poll> request`,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Poll{Op: epp.PollRequest},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><poll op="req"></poll></command></epp>`,
+			false,
+		},
+		{
+			`<poll> acknowledgement`,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Poll{Op: epp.PollAcknowledge, MessageID: "12345"},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><poll op="ack" msgID="12345"></poll></command></epp>`,
+			false,
+		},
 		{
 			`empty <renew> command`,
 			&epp.EPP{
@@ -1,10 +1,28 @@
 package epp
 
+import (
+	"github.com/domainr/epp2/internal/xml"
+
+	"github.com/domainr/epp2/schema"
+)
+
 // Create represents an EPP <create> command as defined in RFC 5730.
 // See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.1.
 type Create struct {
 	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 create"`
-	// TODO: finish this.
+	Create  CreateType
 }
 
 func (Create) eppAction() {}
+
+// UnmarshalXML implements the xml.Unmarshaler interface. It requires an
+// xml.Decoder with an associated schema.Resolver to correctly decode EPP <create>
+// sub-elements.
+func (c *Create) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return schema.DecodeElements(d, func(v any) error {
+		if create, ok := v.(CreateType); ok {
+			c.Create = create
+		}
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+package epp_test
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/schematest"
+	"github.com/domainr/epp2/schema/std"
+)
+
+func TestCreateRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver schema.Resolver
+		v        any
+		want     string
+		wantErr  bool
+	}{
+		{
+			`<domain:create> command`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Create{
+						Create: &domain.Create{
+							Name:   "example.com",
+							Period: domain.Years(2),
+							Nameservers: &domain.Nameservers{
+								HostObjects: []string{"ns1.example.net", "ns2.example.net"},
+							},
+							Registrant: "jd1234",
+							Contacts: []domain.Contact{
+								{Type: domain.ContactAdmin, ID: "sh8013"},
+								{Type: domain.ContactTech, ID: "sh8013"},
+								{Type: domain.ContactBilling, ID: "sh8013"},
+							},
+							AuthInfo: domain.AuthInfo{
+								Password: domain.Password{Password: "2fooBAR"},
+							},
+						},
+					},
+					ClientTransactionID: "ABC-12345",
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="y">2</domain:period><domain:ns><domain:hostObj>ns1.example.net</domain:hostObj><domain:hostObj>ns2.example.net</domain:hostObj></domain:ns><domain:registrant>jd1234</domain:registrant><domain:contact type="admin">sh8013</domain:contact><domain:contact type="tech">sh8013</domain:contact><domain:contact type="billing">sh8013</domain:contact><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:create></create><clTRID>ABC-12345</clTRID></command></epp>`,
+			false,
+		},
+		{
+			`<domain:create> command with host attributes`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Create{
+						Create: &domain.Create{
+							Name:   "example.com",
+							Period: domain.Months(6),
+							Nameservers: &domain.Nameservers{
+								HostAttributes: []domain.HostAttribute{
+									{Name: "ns1.example.net"},
+									{
+										Name: "ns1.example.com",
+										Addresses: []domain.HostAddress{
+											{IP: domain.IPv4, Address: "192.0.2.2"},
+										},
+									},
+								},
+							},
+							AuthInfo: domain.AuthInfo{
+								Password: domain.Password{Password: "2fooBAR"},
+							},
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><create><domain:create xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="m">6</domain:period><domain:ns><domain:hostAttr><domain:hostName>ns1.example.net</domain:hostName></domain:hostAttr><domain:hostAttr><domain:hostName>ns1.example.com</domain:hostName><domain:hostAddr ip="v4">192.0.2.2</domain:hostAddr></domain:hostAttr></domain:ns><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:create></create></command></epp>`,
+			false,
+		},
+		{
+			`<domain:creData> response`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Response{
+					Results: []epp.Result{
+						{
+							Code:    epp.Success,
+							Message: epp.Success.Message(),
+						},
+					},
+					Data: []epp.ResponseData{
+						&domain.CreateData{
+							Name:       "example.com",
+							CreateDate: std.ParseTime("1999-04-03T22:00:00Z").Pointer(),
+							ExpireDate: std.ParseTime("2001-04-03T22:00:00Z").Pointer(),
+						},
+					},
+					TransactionID: epp.TransactionID{
+						Client: "ABC-12345",
+						Server: "54321-XYZ",
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="1000"><msg lang="en">Command completed successfully</msg></result><resData><domain:creData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:crDate>1999-04-03T22:00:00Z</domain:crDate><domain:exDate>2001-04-03T22:00:00Z</domain:exDate></domain:creData></resData><trID><clTRID>ABC-12345</clTRID><svTRID>54321-XYZ</svTRID></trID></response></epp>`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, tt.resolver, tt.v, tt.want, tt.wantErr)
+		})
+	}
+}
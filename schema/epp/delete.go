@@ -1,10 +1,28 @@
 package epp
 
+import (
+	"github.com/domainr/epp2/internal/xml"
+
+	"github.com/domainr/epp2/schema"
+)
+
 // Delete represents an EPP <delete> command as defined in RFC 5730.
-// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.1.
+// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.2.
 type Delete struct {
 	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 delete"`
-	// TODO: finish this.
+	Delete  DeleteType
 }
 
 func (Delete) eppAction() {}
+
+// UnmarshalXML implements the xml.Unmarshaler interface. It requires an
+// xml.Decoder with an associated schema.Resolver to correctly decode EPP <delete>
+// sub-elements.
+func (del *Delete) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return schema.DecodeElements(d, func(v any) error {
+		if delete, ok := v.(DeleteType); ok {
+			del.Delete = delete
+		}
+		return nil
+	})
+}
@@ -0,0 +1,42 @@
+package epp_test
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/schematest"
+)
+
+func TestDeleteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver schema.Resolver
+		v        any
+		want     string
+		wantErr  bool
+	}{
+		{
+			`<domain:delete> command`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Delete{
+						Delete: &domain.Delete{
+							Name: "example.com",
+						},
+					},
+					ClientTransactionID: "ABC-12345",
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><delete><domain:delete xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:delete></delete><clTRID>ABC-12345</clTRID></command></epp>`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, tt.resolver, tt.v, tt.want, tt.wantErr)
+		})
+	}
+}
@@ -1,10 +1,28 @@
 package epp
 
+import (
+	"github.com/domainr/epp2/internal/xml"
+
+	"github.com/domainr/epp2/schema"
+)
+
 // Info represents an EPP <info> command as defined in RFC 5730.
 // See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.2.2.
 type Info struct {
 	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 info"`
-	// TODO: InfoType
+	Info    InfoType
 }
 
 func (Info) eppAction() {}
+
+// UnmarshalXML implements the xml.Unmarshaler interface. It requires an
+// xml.Decoder with an associated schema.Resolver to correctly decode EPP <info>
+// sub-elements.
+func (i *Info) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return schema.DecodeElements(d, func(v any) error {
+		if info, ok := v.(InfoType); ok {
+			i.Info = info
+		}
+		return nil
+	})
+}
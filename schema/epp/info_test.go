@@ -0,0 +1,139 @@
+package epp_test
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/schematest"
+	"github.com/domainr/epp2/schema/std"
+	"github.com/domainr/epp2/status"
+)
+
+func TestInfoRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver schema.Resolver
+		v        any
+		want     string
+		wantErr  bool
+	}{
+		{
+			`simple <domain:info> command`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Info{
+						Info: &domain.Info{
+							Name: domain.InfoName{Name: "example.com"},
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:info></info></command></epp>`,
+			false,
+		},
+		{
+			`<domain:info> command with hosts and authInfo`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Info{
+						Info: &domain.Info{
+							Name: domain.InfoName{Hosts: domain.HostsAll, Name: "example.com"},
+							AuthInfo: &domain.AuthInfo{
+								Password: domain.Password{Password: "2fooBAR"},
+							},
+						},
+					},
+					ClientTransactionID: "ABC-12345",
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><info><domain:info xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name hosts="all">example.com</domain:name><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:info></info><clTRID>ABC-12345</clTRID></command></epp>`,
+			false,
+		},
+		{
+			`<domain:infData> response`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Response{
+					Results: []epp.Result{
+						{
+							Code:    epp.Success,
+							Message: epp.Success.Message(),
+						},
+					},
+					Data: []epp.ResponseData{
+						&domain.InfoData{
+							Name: "example.com",
+							ROID: "EXAMPLE1-REP",
+							Statuses: []domain.Status{
+								{Status: status.OK},
+								{Status: status.ClientHold, Lang: "en", Reason: "Payment overdue."},
+							},
+							Registrant: "jd1234",
+							Contacts: []domain.Contact{
+								{Type: domain.ContactAdmin, ID: "sh8013"},
+								{Type: domain.ContactTech, ID: "sh8013"},
+							},
+							Nameservers: &domain.Nameservers{
+								HostObjects: []string{"ns1.example.com", "ns1.example.net"},
+							},
+							Hosts:        []string{"ns1.example.com", "ns2.example.com"},
+							ClientID:     "ClientX",
+							CreatorID:    "ClientY",
+							CreateDate:   std.ParseTime("1999-04-03T22:00:00Z").Pointer(),
+							UpdaterID:    "ClientX",
+							UpdateDate:   std.ParseTime("1999-12-03T09:00:00Z").Pointer(),
+							ExpireDate:   std.ParseTime("2005-04-03T22:00:00Z").Pointer(),
+							TransferDate: std.ParseTime("2000-04-08T09:00:00Z").Pointer(),
+							AuthInfo: &domain.AuthInfo{
+								Password: domain.Password{Password: "2fooBAR"},
+							},
+						},
+					},
+					TransactionID: epp.TransactionID{
+						Client: "ABC-12345",
+						Server: "54322-XYZ",
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="1000"><msg lang="en">Command completed successfully</msg></result><resData><domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:roid>EXAMPLE1-REP</domain:roid><domain:status s="ok"></domain:status><domain:status s="clientHold" lang="en">Payment overdue.</domain:status><domain:registrant>jd1234</domain:registrant><domain:contact type="admin">sh8013</domain:contact><domain:contact type="tech">sh8013</domain:contact><domain:ns><domain:hostObj>ns1.example.com</domain:hostObj><domain:hostObj>ns1.example.net</domain:hostObj></domain:ns><domain:host>ns1.example.com</domain:host><domain:host>ns2.example.com</domain:host><domain:clID>ClientX</domain:clID><domain:crID>ClientY</domain:crID><domain:crDate>1999-04-03T22:00:00Z</domain:crDate><domain:upID>ClientX</domain:upID><domain:upDate>1999-12-03T09:00:00Z</domain:upDate><domain:exDate>2005-04-03T22:00:00Z</domain:exDate><domain:trDate>2000-04-08T09:00:00Z</domain:trDate><domain:authInfo><domain:pw>2fooBAR</domain:pw></domain:authInfo></domain:infData></resData><trID><clTRID>ABC-12345</clTRID><svTRID>54322-XYZ</svTRID></trID></response></epp>`,
+			false,
+		},
+		{
+			`<domain:infData> response with host attributes`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Response{
+					Data: []epp.ResponseData{
+						&domain.InfoData{
+							Name: "example.com",
+							ROID: "EXAMPLE1-REP",
+							Nameservers: &domain.Nameservers{
+								HostAttributes: []domain.HostAttribute{
+									{
+										Name: "ns1.example.net",
+										Addresses: []domain.HostAddress{
+											{IP: domain.IPv4, Address: "192.0.2.2"},
+											{IP: domain.IPv6, Address: "1080:0:0:0:8:800:200C:417A"},
+										},
+									},
+								},
+							},
+							ClientID: "ClientX",
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><resData><domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:roid>EXAMPLE1-REP</domain:roid><domain:ns><domain:hostAttr><domain:hostName>ns1.example.net</domain:hostName><domain:hostAddr ip="v4">192.0.2.2</domain:hostAddr><domain:hostAddr ip="v6">1080:0:0:0:8:800:200C:417A</domain:hostAddr></domain:hostAttr></domain:ns><domain:clID>ClientX</domain:clID></domain:infData></resData><trID><clTRID></clTRID><svTRID></svTRID></trID></response></epp>`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, tt.resolver, tt.v, tt.want, tt.wantErr)
+		})
+	}
+}
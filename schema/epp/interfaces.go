@@ -22,6 +22,48 @@ type CheckType interface {
 	EPPCheck()
 }
 
+// CreateType is a child element of EPP <create>.
+//
+// It is represented as a <create> element with an object-specific namespace.
+type CreateType interface {
+	EPPCreate()
+}
+
+// DeleteType is a child element of EPP <delete>.
+//
+// It is represented as a <delete> element with an object-specific namespace.
+type DeleteType interface {
+	EPPDelete()
+}
+
+// InfoType is a child element of EPP <info>.
+//
+// It is represented as an <info> element with an object-specific namespace.
+type InfoType interface {
+	EPPInfo()
+}
+
+// RenewType is a child element of EPP <renew>.
+//
+// It is represented as a <renew> element with an object-specific namespace.
+type RenewType interface {
+	EPPRenew()
+}
+
+// TransferType is a child element of EPP <transfer>.
+//
+// It is represented as a <transfer> element with an object-specific namespace.
+type TransferType interface {
+	EPPTransfer()
+}
+
+// UpdateType is a child element of EPP <update>.
+//
+// It is represented as an <update> element with an object-specific namespace.
+type UpdateType interface {
+	EPPUpdate()
+}
+
 // Value is a generic EPP result value.
 //
 // It is represented as a <value> element with an object or extension-specific
@@ -4,7 +4,19 @@ package epp
 // See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.2.3.
 type Poll struct {
 	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 poll"`
-	// TODO: finish this.
+
+	// Op is the poll operation, either [PollRequest] or [PollAcknowledge].
+	Op string `xml:"op,attr,omitempty"`
+
+	// MessageID identifies the message being acknowledged.
+	// It is required when Op is [PollAcknowledge].
+	MessageID string `xml:"msgID,attr,omitempty"`
 }
 
 func (Poll) eppAction() {}
+
+// Poll operations.
+const (
+	PollRequest     = "req"
+	PollAcknowledge = "ack"
+)
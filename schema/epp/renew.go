@@ -1,10 +1,28 @@
 package epp
 
+import (
+	"github.com/domainr/epp2/internal/xml"
+
+	"github.com/domainr/epp2/schema"
+)
+
 // Renew represents an EPP <renew> command as defined in RFC 5730.
-// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.1.
+// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.3.
 type Renew struct {
 	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 renew"`
-	// TODO: finish this.
+	Renew   RenewType
 }
 
 func (Renew) eppAction() {}
+
+// UnmarshalXML implements the xml.Unmarshaler interface. It requires an
+// xml.Decoder with an associated schema.Resolver to correctly decode EPP <renew>
+// sub-elements.
+func (r *Renew) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return schema.DecodeElements(d, func(v any) error {
+		if renew, ok := v.(RenewType); ok {
+			r.Renew = renew
+		}
+		return nil
+	})
+}
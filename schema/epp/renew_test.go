@@ -0,0 +1,83 @@
+package epp_test
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/schematest"
+	"github.com/domainr/epp2/schema/std"
+)
+
+func TestRenewRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver schema.Resolver
+		v        any
+		want     string
+		wantErr  bool
+	}{
+		{
+			`<domain:renew> command`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Renew{
+						Renew: &domain.Renew{
+							Name:              "example.com",
+							CurrentExpireDate: std.ParseDate("2000-04-03"),
+							Period:            domain.Years(5),
+						},
+					},
+					ClientTransactionID: "ABC-12345",
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><renew><domain:renew xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:curExpDate>2000-04-03</domain:curExpDate><domain:period unit="y">5</domain:period></domain:renew></renew><clTRID>ABC-12345</clTRID></command></epp>`,
+			false,
+		},
+		{
+			`<domain:renew> command without period`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Renew{
+						Renew: &domain.Renew{
+							Name:              "example.com",
+							CurrentExpireDate: std.ParseDate("2000-04-03"),
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><renew><domain:renew xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:curExpDate>2000-04-03</domain:curExpDate></domain:renew></renew></command></epp>`,
+			false,
+		},
+		{
+			`<domain:renData> response`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Response{
+					Results: []epp.Result{
+						{
+							Code:    epp.Success,
+							Message: epp.Success.Message(),
+						},
+					},
+					Data: []epp.ResponseData{
+						&domain.RenewData{
+							Name:       "example.com",
+							ExpireDate: std.ParseTime("2005-04-03T22:00:00Z").Pointer(),
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="1000"><msg lang="en">Command completed successfully</msg></result><resData><domain:renData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:exDate>2005-04-03T22:00:00Z</domain:exDate></domain:renData></resData><trID><clTRID></clTRID><svTRID></svTRID></trID></response></epp>`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, tt.resolver, tt.v, tt.want, tt.wantErr)
+		})
+	}
+}
@@ -1,5 +1,12 @@
 package epp
 
+import (
+	"fmt"
+
+	"github.com/domainr/epp2/internal/xml"
+	"github.com/domainr/epp2/schema"
+)
+
 // Response represents an EPP server <response> as defined in RFC 5730.
 // See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.6.
 type Response struct {
@@ -16,7 +23,7 @@ type Response struct {
 	// Data is the OPTIONAL <resData> (response data) element
 	// contains child elements specific to the command and associated
 	// object.
-	Data []ResponseData
+	Data []ResponseData `xml:"resData>data,omitempty"`
 
 	// Extensions represents an OPTIONAL <extension> element that MAY
 	// be used for server-defined response extensions.
@@ -31,6 +38,39 @@ type Response struct {
 
 func (Response) eppBody() {}
 
+// UnmarshalXML implements the xml.Unmarshaler interface.
+// It requires an xml.Decoder with an associated schema.Resolver to
+// correctly decode EPP <resData> sub-elements.
+func (r *Response) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type T Response
+	var v struct {
+		*T
+		Data responseDataWrapper `xml:"resData"`
+	}
+	v.T = (*T)(r)
+	err := d.DecodeElement(&v, &start)
+	if err != nil {
+		return err
+	}
+	r.Data = v.Data.Data
+	return nil
+}
+
+type responseDataWrapper struct {
+	Data []ResponseData
+}
+
+// UnmarshalXML requires an xml.Decoder with an associated schema.Resolver to
+// property decode EPP <resData> child elements.
+func (w *responseDataWrapper) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return schema.DecodeElements(d, func(v any) error {
+		if data, ok := v.(ResponseData); ok {
+			w.Data = append(w.Data, data)
+		}
+		return nil
+	})
+}
+
 // Result represents an EPP server <result> as defined in RFC 5730.
 type Result struct {
 	Code            ResultCode `xml:"code,attr"`
@@ -39,6 +79,16 @@ type Result struct {
 	ExtensionValues []ExtensionValue `xml:"extValue,omitempty"`
 }
 
+// Error implements the error interface, so a Result can be returned as an
+// error value. The returned string includes the result code and message.
+func (r *Result) Error() string {
+	msg := r.Message.Value
+	if msg == "" {
+		msg = r.Code.String()
+	}
+	return fmt.Sprintf("epp: result code %04d: %s", r.Code, msg)
+}
+
 // ExtensionValue wraps an EPP result extension value within a <result>.
 type ExtensionValue struct {
 	Value  Value
@@ -1,10 +1,49 @@
 package epp
 
+import (
+	"github.com/domainr/epp2/internal/xml"
+
+	"github.com/domainr/epp2/schema"
+)
+
 // Transfer represents an EPP <transfer> command as defined in RFC 5730.
-// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.2.4.
+// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.2.4
+// and https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.4.
 type Transfer struct {
 	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 transfer"`
-	// TODO: finish this.
+
+	// Op is the transfer operation, one of [TransferRequest],
+	// [TransferApprove], [TransferReject], [TransferCancel], or
+	// [TransferQuery].
+	Op string `xml:"op,attr,omitempty"`
+
+	Transfer TransferType
 }
 
 func (Transfer) eppAction() {}
+
+// Transfer operations.
+const (
+	TransferRequest = "request"
+	TransferApprove = "approve"
+	TransferReject  = "reject"
+	TransferCancel  = "cancel"
+	TransferQuery   = "query"
+)
+
+// UnmarshalXML implements the xml.Unmarshaler interface. It requires an
+// xml.Decoder with an associated schema.Resolver to correctly decode EPP <transfer>
+// sub-elements.
+func (t *Transfer) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, a := range start.Attr {
+		if a.Name.Local == "op" {
+			t.Op = a.Value
+		}
+	}
+	return schema.DecodeElements(d, func(v any) error {
+		if transfer, ok := v.(TransferType); ok {
+			t.Transfer = transfer
+		}
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+package epp_test
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/schematest"
+	"github.com/domainr/epp2/schema/std"
+)
+
+func TestTransferRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver schema.Resolver
+		v        any
+		want     string
+		wantErr  bool
+	}{
+		{
+			`<domain:transfer> request`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Transfer{
+						Op: epp.TransferRequest,
+						Transfer: &domain.Transfer{
+							Name:   "example.com",
+							Period: domain.Years(1),
+							AuthInfo: &domain.AuthInfo{
+								Password: domain.Password{ROID: "JD1234-REP", Password: "2fooBAR"},
+							},
+						},
+					},
+					ClientTransactionID: "ABC-12345",
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="request"><domain:transfer xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:period unit="y">1</domain:period><domain:authInfo><domain:pw roid="JD1234-REP">2fooBAR</domain:pw></domain:authInfo></domain:transfer></transfer><clTRID>ABC-12345</clTRID></command></epp>`,
+			false,
+		},
+		{
+			`<domain:transfer> query`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Transfer{
+						Op: epp.TransferQuery,
+						Transfer: &domain.Transfer{
+							Name: "example.com",
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><transfer op="query"><domain:transfer xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name></domain:transfer></transfer></command></epp>`,
+			false,
+		},
+		{
+			`<domain:trnData> response`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Response{
+					Results: []epp.Result{
+						{
+							Code:    epp.SuccessPending,
+							Message: epp.SuccessPending.Message(),
+						},
+					},
+					Data: []epp.ResponseData{
+						&domain.TransferData{
+							Name:         "example.com",
+							Status:       domain.TransferPending,
+							RequestingID: "ClientX",
+							RequestDate:  std.ParseTime("2000-06-08T22:00:00Z").Pointer(),
+							ActingID:     "ClientY",
+							ActionDate:   std.ParseTime("2000-06-13T22:00:00Z").Pointer(),
+							ExpireDate:   std.ParseTime("2002-09-08T22:00:00Z").Pointer(),
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="1001"><msg lang="en">Command completed successfully; action pending</msg></result><resData><domain:trnData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:trStatus>pending</domain:trStatus><domain:reID>ClientX</domain:reID><domain:reDate>2000-06-08T22:00:00Z</domain:reDate><domain:acID>ClientY</domain:acID><domain:acDate>2000-06-13T22:00:00Z</domain:acDate><domain:exDate>2002-09-08T22:00:00Z</domain:exDate></domain:trnData></resData><trID><clTRID></clTRID><svTRID></svTRID></trID></response></epp>`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, tt.resolver, tt.v, tt.want, tt.wantErr)
+		})
+	}
+}
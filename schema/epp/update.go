@@ -1,10 +1,28 @@
 package epp
 
+import (
+	"github.com/domainr/epp2/internal/xml"
+
+	"github.com/domainr/epp2/schema"
+)
+
 // Update represents an EPP <update> command as defined in RFC 5730.
-// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.1.
+// See https://www.rfc-editor.org/rfc/rfc5730.html#section-2.9.3.5.
 type Update struct {
 	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 update"`
-	// TODO: finish this.
+	Update  UpdateType
 }
 
 func (Update) eppAction() {}
+
+// UnmarshalXML implements the xml.Unmarshaler interface. It requires an
+// xml.Decoder with an associated schema.Resolver to correctly decode EPP <update>
+// sub-elements.
+func (u *Update) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return schema.DecodeElements(d, func(v any) error {
+		if update, ok := v.(UpdateType); ok {
+			u.Update = update
+		}
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package epp_test
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/schema"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+	"github.com/domainr/epp2/schema/schematest"
+	"github.com/domainr/epp2/schema/std"
+	"github.com/domainr/epp2/status"
+)
+
+func TestUpdateRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver schema.Resolver
+		v        any
+		want     string
+		wantErr  bool
+	}{
+		{
+			`<domain:update> command`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Update{
+						Update: &domain.Update{
+							Name: "example.com",
+							Add: &domain.UpdateValues{
+								Nameservers: &domain.Nameservers{
+									HostObjects: []string{"ns2.example.com"},
+								},
+								Contacts: []domain.Contact{
+									{Type: domain.ContactTech, ID: "mak21"},
+								},
+								Statuses: []domain.Status{
+									{Status: status.ClientHold, Lang: "en", Reason: "Payment overdue."},
+								},
+							},
+							Remove: &domain.UpdateValues{
+								Nameservers: &domain.Nameservers{
+									HostObjects: []string{"ns1.example.com"},
+								},
+								Contacts: []domain.Contact{
+									{Type: domain.ContactTech, ID: "sh8013"},
+								},
+								Statuses: []domain.Status{
+									{Status: status.ClientUpdateProhibited},
+								},
+							},
+							Change: &domain.UpdateChange{
+								Registrant: std.StringPointer("sh8013"),
+								AuthInfo: &domain.AuthInfo{
+									Password: domain.Password{Password: "2BARfoo"},
+								},
+							},
+						},
+					},
+					ClientTransactionID: "ABC-12345",
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:add><domain:ns><domain:hostObj>ns2.example.com</domain:hostObj></domain:ns><domain:contact type="tech">mak21</domain:contact><domain:status s="clientHold" lang="en">Payment overdue.</domain:status></domain:add><domain:rem><domain:ns><domain:hostObj>ns1.example.com</domain:hostObj></domain:ns><domain:contact type="tech">sh8013</domain:contact><domain:status s="clientUpdateProhibited"></domain:status></domain:rem><domain:chg><domain:registrant>sh8013</domain:registrant><domain:authInfo><domain:pw>2BARfoo</domain:pw></domain:authInfo></domain:chg></domain:update></update><clTRID>ABC-12345</clTRID></command></epp>`,
+			false,
+		},
+		{
+			`<domain:update> command removing registrant`,
+			domain.Schema,
+			&epp.EPP{
+				Body: &epp.Command{
+					Action: &epp.Update{
+						Update: &domain.Update{
+							Name: "example.com",
+							Change: &domain.UpdateChange{
+								Registrant: std.StringPointer(""),
+							},
+						},
+					},
+				},
+			},
+			`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.com</domain:name><domain:chg><domain:registrant></domain:registrant></domain:chg></domain:update></update></command></epp>`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, tt.resolver, tt.v, tt.want, tt.wantErr)
+		})
+	}
+}
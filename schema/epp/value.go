@@ -0,0 +1,20 @@
+package epp
+
+import "github.com/domainr/epp2/internal/xml"
+
+// ElementValue is a generic [Value] that identifies an element sent by a
+// client that caused an error, such as an unsupported <objURI> in a <login>
+// command. It is serialized as a <value> element containing a copy of the
+// offending element.
+type ElementValue struct {
+	XMLName struct{} `xml:"urn:ietf:params:xml:ns:epp-1.0 value"`
+	Element Element
+}
+
+func (ElementValue) EPPValue() {}
+
+// Element represents a simple XML element containing only character data.
+type Element struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
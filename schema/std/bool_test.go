@@ -3,6 +3,7 @@ package std
 import (
 	"testing"
 
+	"github.com/domainr/epp2/internal/xml"
 	"github.com/domainr/epp2/schema/schematest"
 )
 
@@ -82,3 +83,27 @@ func TestBool(t *testing.T) {
 		})
 	}
 }
+
+func TestBoolUnmarshalXMLAttr(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Bool
+	}{
+		{"", false},
+		{"0", false},
+		{"false", false},
+		{"F", false},
+		{"1", true},
+		{"true", true},
+	}
+	for _, tt := range tests {
+		b := !tt.want
+		err := b.UnmarshalXMLAttr(&xml.Attr{Value: tt.value})
+		if err != nil {
+			t.Errorf("UnmarshalXMLAttr(%q): err == %v", tt.value, err)
+		}
+		if b != tt.want {
+			t.Errorf("UnmarshalXMLAttr(%q): got %t, expected %t", tt.value, b, tt.want)
+		}
+	}
+}
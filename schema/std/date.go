@@ -0,0 +1,38 @@
+package std
+
+import (
+	"time"
+)
+
+// Date represents a W3C XML date value, without a time of day.
+// See https://www.w3.org/TR/xmlschema-2/#date.
+type Date struct {
+	time.Time
+}
+
+// ParseDate parses a date string in the form YYYY-MM-DD.
+// It returns an empty value if unable to parse s.
+func ParseDate(s string) Date {
+	tt, _ := time.Parse(time.DateOnly, s)
+	return Date{tt}
+}
+
+// Pointer returns a pointer to d, useful for declaring composite literals.
+func (d Date) Pointer() *Date {
+	return &d
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.Format(time.DateOnly)), nil
+}
+
+// UnmarshalText implements an encoding.TextUnmarshaler that ignores parsing errors.
+// An optional time zone suffix is ignored.
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) > len(time.DateOnly) {
+		text = text[:len(time.DateOnly)]
+	}
+	*d = ParseDate(string(text))
+	return nil
+}
@@ -0,0 +1,63 @@
+package std
+
+import (
+	"testing"
+
+	"github.com/domainr/epp2/schema/schematest"
+)
+
+func TestDate(t *testing.T) {
+	type T struct {
+		XMLName struct{} `xml:"example"`
+		Value   *Date    `xml:"when"`
+		Attr    *Date    `xml:"when,attr,omitempty"`
+	}
+
+	tests := []struct {
+		name    string
+		v       any
+		want    string
+		wantErr bool
+	}{
+		{
+			`no tags`,
+			&T{},
+			`<example></example>`,
+			false,
+		},
+		{
+			`zero value chardata`,
+			&T{Value: &Date{}},
+			`<example><when>0001-01-01</when></example>`,
+			false,
+		},
+		{
+			`chardata`,
+			&T{Value: ParseDate("2000-04-03").Pointer()},
+			`<example><when>2000-04-03</when></example>`,
+			false,
+		},
+		{
+			`attr`,
+			&T{Attr: ParseDate("2000-04-03").Pointer()},
+			`<example when="2000-04-03"></example>`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schematest.RoundTrip(t, nil, tt.v, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestDateTimeZone(t *testing.T) {
+	var d Date
+	err := d.UnmarshalText([]byte("2000-04-03Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ParseDate("2000-04-03"); d != want {
+		t.Errorf("UnmarshalText(): got %v, expected %v", d, want)
+	}
+}
@@ -2,18 +2,27 @@ package epp
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"io"
 	"net"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
-	"github.com/domainr/epp2/protocol/dataunit"
+	"github.com/domainr/epp2/protocol"
 	"github.com/domainr/epp2/schema/epp"
 )
 
-// server is an EPP version 1.0 server.
-type server struct {
+// Server is an EPP version 1.0 server.
+type Server struct {
 	// Name is the name of this EPP server. It is sent to clients in a EPP
-	// <greeting> message. If empty, a reasonable default will be used.
+	// <greeting> message if Config.ServerName is empty. If both are empty,
+	// DefaultServerName is used.
 	Name string
 
 	// Config describes the EPP server configuration. Configuration
@@ -24,18 +33,166 @@ type server struct {
 	// The connection will be closed when Handler returns.
 	Handler func(Session) error
 
+	// MaxLoginAttempts is the number of failed <login> attempts allowed
+	// per session. The final failed attempt receives a 2501 response, and
+	// the session is closed. If zero, failed logins are not limited.
+	MaxLoginAttempts int
+
+	// MaxSessions limits the number of concurrent logged-in sessions. A
+	// <login> exceeding the limit receives a 2502 response, and the
+	// session is closed. If zero, sessions are not limited.
+	MaxSessions int
+
+	// MaxClientSessions limits the number of concurrent logged-in
+	// sessions for each client ID. A <login> exceeding the limit receives
+	// a 2502 response, and the session is closed. If zero, sessions per
+	// client ID are not limited.
+	MaxClientSessions int
+
+	// MaxListenerSessions limits the number of concurrent sessions
+	// accepted by Serve from each listener. Excess connections receive a
+	// <greeting>, then a 2502 response to their first command, and are
+	// closed. If zero, sessions per listener are not limited.
+	MaxListenerSessions int
+
+	// MaxInFlight limits the number of commands read from a session that
+	// have not been responded to. When the limit is reached, the session
+	// stops reading from the connection until a response is written. If
+	// zero, commands in flight are not limited.
+	MaxInFlight int
+
+	// CertificateClientIDs, if not nil, returns the client IDs permitted
+	// to log in over a TLS connection with a verified client certificate,
+	// e.g. by matching its subject, subject alternative names, or
+	// [CertificateFingerprint]. A <login> with a client ID not returned by
+	// CertificateClientIDs receives a 2200 response. If set, a <login>
+	// without a verified client certificate also receives a 2200 response.
+	CertificateClientIDs func(cert *x509.Certificate) []string
+
+	// IdleTimeout is the maximum duration a session may wait for the next
+	// command from the client while no commands are in flight. An idle
+	// session is closed. If zero, there is no idle timeout.
+	IdleTimeout time.Duration
+
+	// WriteTimeout is the maximum duration for writing each response,
+	// including any time spent waiting for responses to earlier commands.
+	// If a write times out, the session is closed. If zero, there is no
+	// write timeout.
+	WriteTimeout time.Duration
+
+	// MaxLifetime is the maximum duration of a session. After MaxLifetime,
+	// commands in flight are allowed to complete, new commands are
+	// answered with 2500, and the session is closed. If zero, sessions
+	// have no maximum lifetime.
+	MaxLifetime time.Duration
+
 	inShutdown atomic.Bool
 
 	mu            sync.Mutex
 	listeners     map[net.Listener]struct{}
 	listenerGroup sync.WaitGroup
+	sessions      map[*session]struct{}
+	loggedIn      int            // logged-in sessions, see addLogin
+	clients       map[string]int // logged-in sessions per client ID
+	accepted      map[net.Listener]int
 }
 
-func (s *server) shuttingDown() bool {
+// DefaultServerName is sent in the <greeting> of a [Server] without a Name.
+const DefaultServerName = "EPP Server"
+
+func (s *Server) shuttingDown() bool {
 	return s.inShutdown.Load()
 }
 
-func (s *server) trackListener(l net.Listener, add bool) bool {
+// Shutdown gracefully shuts down the server without interrupting any active
+// sessions. Shutdown works by first closing all open listeners, then closing
+// all idle sessions, and then waiting indefinitely for sessions to return to
+// idle and then close. A session is idle when every command read from the
+// client has been responded to. Commands received from the client after
+// Shutdown is called are answered with a 2500 response, and the session is
+// closed.
+//
+// If the provided Context expires before the shutdown is complete, Shutdown
+// returns the Context's error, otherwise it returns any error returned from
+// closing the Server's underlying listeners.
+//
+// Once Shutdown has been called on a server, it may not be reused; future calls
+// to methods such as Serve will return [ErrServerClosed].
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.inShutdown.Store(true)
+
+	s.mu.Lock()
+	err := s.closeListenersLocked()
+	s.mu.Unlock()
+	s.listenerGroup.Wait()
+
+	const pollInterval = 10 * time.Millisecond
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
+	for {
+		if s.closeIdleSessions() {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+	}
+}
+
+// Close immediately closes all active listeners and all sessions.
+// For a graceful shutdown, use [Server.Shutdown].
+//
+// Close returns any error returned from closing the Server's underlying
+// listeners.
+func (s *Server) Close() error {
+	s.inShutdown.Store(true)
+
+	s.mu.Lock()
+	err := s.closeListenersLocked()
+	sessions := make([]*session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+	s.listenerGroup.Wait()
+
+	for _, sess := range sessions {
+		sess.close(ErrServerClosed)
+	}
+	return err
+}
+
+// closeListenersLocked closes all tracked listeners. s.mu must be held.
+func (s *Server) closeListenersLocked() error {
+	var errs []error
+	for l := range s.listeners {
+		errs = append(errs, l.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// closeIdleSessions closes all idle sessions and reports whether the server
+// has no remaining sessions.
+func (s *Server) closeIdleSessions() bool {
+	s.mu.Lock()
+	var idle []*session
+	for sess := range s.sessions {
+		if sess.idle() {
+			idle = append(idle, sess)
+		}
+	}
+	quiescent := len(s.sessions) == len(idle)
+	s.mu.Unlock()
+
+	for _, sess := range idle {
+		sess.close(ErrServerClosed)
+	}
+	return quiescent
+}
+
+func (s *Server) trackListener(l net.Listener, add bool) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.listeners == nil {
@@ -54,10 +211,80 @@ func (s *server) trackListener(l net.Listener, add bool) bool {
 	return true
 }
 
+func (s *Server) trackSession(sess *session, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sessions == nil {
+		s.sessions = make(map[*session]struct{})
+	}
+	if add {
+		if s.shuttingDown() {
+			return false
+		}
+		s.sessions[sess] = struct{}{}
+		if l := sess.listener; l != nil {
+			if s.accepted == nil {
+				s.accepted = make(map[net.Listener]int)
+			}
+			if s.MaxListenerSessions > 0 && s.accepted[l] >= s.MaxListenerSessions {
+				sess.limited.Store(true)
+			} else {
+				s.accepted[l]++
+				sess.accepted = true
+			}
+		}
+	} else {
+		delete(s.sessions, sess)
+		if sess.accepted {
+			sess.accepted = false
+			s.accepted[sess.listener]--
+			if s.accepted[sess.listener] <= 0 {
+				delete(s.accepted, sess.listener)
+			}
+		}
+		if sess.counted {
+			sess.counted = false
+			s.loggedIn--
+			s.clients[sess.clientID]--
+			if s.clients[sess.clientID] <= 0 {
+				delete(s.clients, sess.clientID)
+			}
+		}
+	}
+	return true
+}
+
+// addLogin counts sess as logged in with clientID, reporting false if
+// s.MaxSessions or s.MaxClientSessions would be exceeded.
+func (s *Server) addLogin(sess *session, clientID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess.counted {
+		return true
+	}
+	if s.MaxSessions > 0 && s.loggedIn >= s.MaxSessions {
+		return false
+	}
+	if s.MaxClientSessions > 0 && s.clients[clientID] >= s.MaxClientSessions {
+		return false
+	}
+	if s.clients == nil {
+		s.clients = make(map[string]int)
+	}
+	sess.counted = true
+	sess.clientID = clientID
+	s.loggedIn++
+	s.clients[clientID]++
+	return true
+}
+
 // Serve accepts incoming connections on [net.Listener] l,
 // creating a new service goroutine for each connection.
 // The service goroutines read commands and then call s.Handler to reply to them.
-func (s *server) Serve(l net.Listener) error {
+//
+// Serve always returns a non-nil error. After [Server.Shutdown] or
+// [Server.Close], the returned error is [ErrServerClosed].
+func (s *Server) Serve(l net.Listener) error {
 	if !s.trackListener(l, true) {
 		return ErrServerClosed
 	}
@@ -70,25 +297,86 @@ func (s *server) Serve(l net.Listener) error {
 			}
 			return err
 		}
-		var _ = conn
-		// pconn := protocol.NewConn(&dataunit.NetConn{Conn: conn}, s.Config.Schemas)
-		// go s.Handle(pconn)
+		go s.serve(conn, l)
 	}
 }
 
 // Handle accepts a connection and receives and processes EPP commands.
-func (s *server) Handle(conn net.Conn) error {
-	if s.shuttingDown() {
+// It sends a <greeting> to the client, then calls s.Handler with a [Session].
+// The connection is closed when Handle returns.
+//
+// The Session enforces the EPP session lifecycle described in RFC 5730:
+// <hello> is answered with a <greeting>, a successful <login> is required
+// before any other command, <login> is only permitted once per session, and
+// <logout> is answered with 1500 and closes the session. Commands violating
+// the lifecycle are answered with 2002 without being returned from
+// ReadCommand. A <login> with an unsupported version, language, object, or
+// extension is rejected before it is returned from ReadCommand. After login,
+// a command for an object or extension not negotiated by the <login> is
+// answered with 2307 or 2103. The negotiated Config is available to handlers
+// via [LoginConfig].
+func (s *Server) Handle(conn net.Conn) error {
+	return s.serve(conn, nil)
+}
+
+// serve handles conn accepted from listener l, which may be nil.
+func (s *Server) serve(conn net.Conn, l net.Listener) error {
+	ctx, cancel := context.WithCancelCause(withState(context.Background()))
+	sess := &session{
+		ctx:      ctx,
+		cancel:   cancel,
+		conn:     conn,
+		server:   s,
+		listener: l,
+		state:    contextState(ctx),
+	}
+	if s.MaxInFlight > 0 {
+		sess.slots = make(chan struct{}, s.MaxInFlight)
+	}
+	defer sess.Close()
+	if !s.trackSession(sess, true) {
 		return ErrServerClosed
 	}
-	session := &session{
-		// ctx:  s.connContext(conn),
-		// conn: conn,
+	defer s.trackSession(sess, false)
+
+	greeting, err := s.greeting()
+	if err != nil {
+		return err
+	}
+	wctx, cancelWrite := sess.writeContext()
+	if tc, ok := conn.(*tls.Conn); ok {
+		err = tc.HandshakeContext(wctx)
+		if err != nil {
+			cancelWrite()
+			return err
+		}
+		if chains := tc.ConnectionState().VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+			sess.cert = chains[0][0]
+		}
+	}
+	sess.s, err = protocol.Serve(wctx, sessionConn{conn, sess}, greeting, s.Config.Schemas...)
+	cancelWrite()
+	if err != nil {
+		return err
+	}
+	if s.IdleTimeout > 0 {
+		// Hold sess.mu so idleTimeout observes sess.idleTimer.
+		sess.mu.Lock()
+		sess.idleTimer = time.AfterFunc(s.IdleTimeout, sess.idleTimeout)
+		sess.mu.Unlock()
+		defer sess.idleTimer.Stop()
 	}
-	return s.handle(session)
+	if s.MaxLifetime > 0 {
+		t := time.AfterFunc(s.MaxLifetime, sess.expire)
+		defer t.Stop()
+	}
+	sess.requests = make(chan request)
+	go sess.read()
+
+	return s.handle(sess)
 }
 
-func (s *server) handle(sess *session) error {
+func (s *Server) handle(sess *session) error {
 	defer sess.Close()
 	if s.Handler == nil {
 		return nil
@@ -96,6 +384,19 @@ func (s *server) handle(sess *session) error {
 	return s.Handler(sess)
 }
 
+// greeting returns an EPP <greeting> describing s. If s.Config does not
+// specify a ServerName, s.Name is used.
+func (s *Server) greeting() (epp.Body, error) {
+	cfg := s.Config
+	if cfg.ServerName == "" {
+		cfg.ServerName = s.Name
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = DefaultServerName
+	}
+	return Greeting(&cfg)
+}
+
 type Session interface {
 	// Context returns the connection Context for this session. The Context
 	// will be canceled if the underlying connection goes away or is closed.
@@ -104,11 +405,30 @@ type Session interface {
 	// ReadCommand reads the next EPP command from the client. An error will
 	// be returned if the underlying connection is closed or an error occurs
 	// reading from the connection.
+	//
+	// If the client sends a malformed command, ReadCommand returns an
+	// error, and the caller should respond with WriteResponse. If the
+	// session Context is done, the session has ended and no response
+	// should be written.
 	ReadCommand() (*epp.Command, error)
 
 	// WriteResponse sends an EPP response to the client. An error will
 	// be returned if the underlying connection is closed or an error occurs
 	// writing to the connection.
+	//
+	// Responses are sent in the order commands were read. Each call to
+	// WriteResponse responds to the oldest command without a response.
+	// If the response is missing a client or server transaction ID,
+	// WriteResponse fills it in.
+	//
+	// A successful response to <login> logs in the session. A failed
+	// response to <login> may be replaced with 2501 if the session has
+	// exceeded the maximum number of login attempts, or a successful
+	// response with 2502 if the server has exceeded its session limit. In
+	// either case the session is closed after the response is written.
+	//
+	// A response with a 25xx result code (see [epp.ResultCode.IsFatal])
+	// closes the session after it is written, as required by RFC 5730.
 	WriteResponse(*epp.Response) error
 
 	// Close closes the session and the underlying connection.
@@ -116,27 +436,461 @@ type Session interface {
 }
 
 type session struct {
-	ctx context.Context
-	s   dataunit.Server // FIXME: this should be a protocol.Server or protocol.Session
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	conn   net.Conn
+	server *Server
+	s      protocol.Server
+	state  *sessionState
+	cert   *x509.Certificate // verified TLS client certificate, if any
+
+	idleTimer *time.Timer   // nil if Server.IdleTimeout is zero
+	expired   atomic.Bool   // Server.MaxLifetime has elapsed
+	limited   atomic.Bool   // Server.MaxListenerSessions was exceeded
+	slots     chan struct{} // commands in flight, nil if Server.MaxInFlight is zero
+
+	// requests receives client requests read from the connection.
+	requests chan request
+
+	mu           sync.Mutex
+	receiving    bool // part of a request has been read from the connection
+	unread       int  // requests read but not yet returned or answered by ReadCommand
+	responders   []pending
+	loggingIn    bool    // a <login> is awaiting a response
+	negotiated   *Config // Config for the pending <login>
+	failedLogins int
+
+	// Guarded by server.mu, see Server.trackSession and Server.addLogin.
+	listener net.Listener
+	accepted bool
+	counted  bool
+	clientID string
 }
 
 var _ Session = &session{}
 
+// request is an EPP message read from a client.
+type request struct {
+	body epp.Body
+	r    protocol.Responder
+	err  error
+}
+
+// pending is a command awaiting a response.
+type pending struct {
+	r                   protocol.Responder
+	clientTransactionID string
+	login               *epp.Login // non-nil if the command is a <login>
+}
+
+// read reads requests from the client until the session Context is canceled.
+// If an error occurs reading from the underlying connection, the session
+// Context is canceled.
+func (s *session) read() {
+	defer close(s.requests)
+	for {
+		if s.slots != nil {
+			select {
+			case <-s.ctx.Done():
+				return
+			case s.slots <- struct{}{}:
+			}
+		}
+		body, r, err := s.s.ServeEPP(s.ctx)
+		if err != nil && body == nil && isConnError(err) {
+			s.cancel(err)
+			return
+		}
+		s.mu.Lock()
+		s.receiving = false
+		s.unread++
+		s.mu.Unlock()
+		s.touch()
+		select {
+		case <-s.ctx.Done():
+			return
+		case s.requests <- request{body, r, err}:
+		}
+	}
+}
+
+func (s *session) config() *Config {
+	return &s.server.Config
+}
+
 func (s *session) Context() context.Context {
 	return s.ctx
 }
 
 func (s *session) ReadCommand() (*epp.Command, error) {
-	// TODO: implement this
-	return nil, nil
+	for {
+		var req request
+		var ok bool
+		select {
+		case <-s.ctx.Done():
+			return nil, context.Cause(s.ctx)
+		case req, ok = <-s.requests:
+		}
+		if !ok {
+			return nil, context.Cause(s.ctx)
+		}
+		if req.err != nil {
+			s.push(pending{r: req.r})
+			return nil, req.err
+		}
+		switch body := req.body.(type) {
+		case *epp.Hello:
+			// Respond to a <hello> with a current <greeting>.
+			greeting, err := s.server.greeting()
+			if err == nil {
+				err = s.respond(req.r, greeting)
+			}
+			s.answered()
+			if err != nil {
+				s.close(err)
+				return nil, err
+			}
+		case *epp.Command:
+			if code, cause := s.closing(); cause != nil {
+				// Reply to new commands during shutdown, after the
+				// session has expired, or if the session exceeds a
+				// limit, with 2500 or 2502.
+				res := errorResponse(s.config(), code)
+				res.TransactionID.Client = body.ClientTransactionID
+				err := s.respond(req.r, res)
+				s.answered()
+				s.close(cause)
+				if err != nil {
+					return nil, err
+				}
+				return nil, cause
+			}
+			login, err := s.admit(body)
+			if err != nil {
+				err = s.reject(req.r, body, err)
+				s.answered()
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			s.push(pending{req.r, body.ClientTransactionID, login})
+			return body, nil
+		default:
+			s.push(pending{r: req.r})
+			return nil, ErrUnexpectedMessage
+		}
+	}
+}
+
+// errLogout is returned by admit for a valid <logout> command.
+const errLogout stringError = "logout"
+
+// admit applies the session state machine to cmd, returning an error if the
+// session should respond to cmd rather than the handler. If cmd is a <login>,
+// admit returns it.
+func (s *session) admit(cmd *epp.Command) (*epp.Login, error) {
+	_, loggedIn := s.state.clientID()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch a := cmd.Action.(type) {
+	case *epp.Login:
+		if loggedIn || s.loggingIn {
+			return nil, epp.ErrCommandUse
+		}
+		cfg, err := ConfigForLogin(s.config(), a)
+		if err != nil {
+			return nil, err
+		}
+		if !s.certificateAllows(a.ClientID) {
+			s.failedLogins++
+			if max := s.server.MaxLoginAttempts; max > 0 && s.failedLogins >= max {
+				return nil, epp.ErrAuthenticationClosing
+			}
+			return nil, epp.ErrAuthentication
+		}
+		s.loggingIn = true
+		s.negotiated = cfg
+		return a, nil
+	case *epp.Logout:
+		if !loggedIn {
+			return nil, epp.ErrCommandUse
+		}
+		return nil, errLogout
+	}
+	if !loggedIn {
+		return nil, epp.ErrCommandUse
+	}
+	return nil, s.permits(cmd)
+}
+
+// permits returns an error if cmd uses an object or extension that was not
+// negotiated by the session's <login>. Extensions in the server's
+// UnannouncedExtensions are always permitted.
+func (s *session) permits(cmd *epp.Command) error {
+	cfg := s.state.config()
+	if cfg == nil {
+		return nil
+	}
+	if ns := xmlName(commandObject(cmd.Action)).Space; ns != "" && !slices.Contains(cfg.Objects, ns) {
+		return epp.ErrUnimplementedObject
+	}
+	for _, ext := range cmd.Extensions {
+		ns := xmlName(ext).Space
+		if ns != "" && !slices.Contains(cfg.Extensions, ns) && !slices.Contains(s.config().UnannouncedExtensions, ns) {
+			return epp.ErrUnimplementedExtension
+		}
+	}
+	return nil
+}
+
+// certificateAllows reports whether the session's TLS client certificate
+// permits a <login> with clientID. See Server.CertificateClientIDs.
+func (s *session) certificateAllows(clientID string) bool {
+	f := s.server.CertificateClientIDs
+	if f == nil {
+		return true
+	}
+	if s.cert == nil {
+		return false
+	}
+	return slices.Contains(f(s.cert), clientID)
+}
+
+// CertificateFingerprint returns the hex-encoded SHA-256 fingerprint of cert,
+// for use with [Server].CertificateClientIDs.
+func CertificateFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// reject responds to cmd with an error on behalf of the handler. If err is
+// errLogout, it responds with 1500. If the response ends the session, the
+// session is closed and reject returns the reason.
+func (s *session) reject(r protocol.Responder, cmd *epp.Command, err error) error {
+	var res *epp.Response
+	if err == errLogout {
+		res = &epp.Response{
+			Results: []epp.Result{{Code: epp.SuccessEnd, Message: epp.SuccessEnd.Message()}},
+			TransactionID: epp.TransactionID{
+				Server: s.config().transactionID(),
+			},
+		}
+	} else {
+		res = errorResponse(s.config(), err)
+	}
+	res.TransactionID.Client = cmd.ClientTransactionID
+	err = s.respond(r, res)
+	if err != nil {
+		s.close(err)
+		return err
+	}
+	if code := res.Results[0].Code; code == epp.SuccessEnd || code.IsFatal() {
+		s.close(ErrClosedConnection)
+		return ErrClosedConnection
+	}
+	return nil
+}
+
+// finishLogin updates the session state after a response r to login.
+// It returns the response to send, and whether the session should be closed
+// after sending it.
+func (s *session) finishLogin(login *epp.Login, r *epp.Response) (*epp.Response, bool) {
+	s.mu.Lock()
+	s.loggingIn = false
+	cfg := s.negotiated
+	s.negotiated = nil
+	if succeeded(r, nil) {
+		s.mu.Unlock()
+		if !s.server.addLogin(s, login.ClientID) {
+			return replaceResult(r, epp.ErrSessionLimitExceeded), true
+		}
+		s.state.login(login.ClientID, cfg)
+		return r, false
+	}
+	s.failedLogins++
+	n := s.failedLogins
+	s.mu.Unlock()
+	if max := s.server.MaxLoginAttempts; max > 0 && n >= max {
+		return replaceResult(r, epp.ErrAuthenticationClosing), true
+	}
+	return r, false
+}
+
+// replaceResult returns a copy of r with a single result code.
+func replaceResult(r *epp.Response, code epp.ResultCode) *epp.Response {
+	return &epp.Response{
+		Results:       []epp.Result{{Code: code, Message: code.Message()}},
+		TransactionID: r.TransactionID,
+	}
 }
 
 func (s *session) WriteResponse(r *epp.Response) error {
-	// TODO: implement this
+	p, ok := s.pop()
+	if !ok {
+		return ErrNoCommand
+	}
+	if r.TransactionID.Client == "" && p.clientTransactionID != "" {
+		tx := *r
+		tx.TransactionID.Client = p.clientTransactionID
+		r = &tx
+	}
+	if r.TransactionID.Server == "" {
+		tx := *r
+		tx.TransactionID.Server = s.server.Config.transactionID()
+		r = &tx
+	}
+	var closing bool
+	if p.login != nil {
+		r, closing = s.finishLogin(p.login, r)
+	}
+	if len(r.Results) > 0 && r.Results[0].Code.IsFatal() {
+		closing = true
+	}
+	err := s.respond(p.r, r)
+	if closing {
+		s.close(r.Results[0].Code)
+	} else if s.expired.Load() && s.idle() {
+		s.close(ErrSessionExpired)
+	}
+	return err
+}
+
+// respond writes body using r, applying Server.WriteTimeout, and releases the
+// in-flight slot held by the request. If writing fails, the session is closed.
+func (s *session) respond(r protocol.Responder, body epp.Body) error {
+	ctx, cancel := s.writeContext()
+	defer cancel()
+	err := r.RespondEPP(ctx, body)
+	if s.slots != nil {
+		<-s.slots
+	}
+	if err != nil {
+		s.close(err)
+		return err
+	}
+	s.touch()
 	return nil
 }
 
+// writeContext returns a Context for writing a single response.
+func (s *session) writeContext() (context.Context, context.CancelFunc) {
+	if s.server.WriteTimeout > 0 {
+		return context.WithTimeout(s.ctx, s.server.WriteTimeout)
+	}
+	return context.WithCancel(s.ctx)
+}
+
+// closing returns a non-nil error if the session should not accept new
+// commands, and the result code to respond with.
+func (s *session) closing() (epp.ResultCode, error) {
+	switch {
+	case s.limited.Load():
+		return epp.ErrSessionLimitExceeded, epp.ErrSessionLimitExceeded
+	case s.server.shuttingDown():
+		return epp.ErrCommandFailedClosing, ErrServerClosed
+	case s.expired.Load():
+		return epp.ErrCommandFailedClosing, ErrSessionExpired
+	}
+	return 0, nil
+}
+
+// touch resets the idle timer.
+func (s *session) touch() {
+	if s.idleTimer != nil {
+		s.idleTimer.Reset(s.server.IdleTimeout)
+	}
+}
+
+// idleTimeout is called when the idle timer fires. It closes the session if no
+// commands are in flight.
+func (s *session) idleTimeout() {
+	if s.idle() {
+		s.close(ErrIdleTimeout)
+		return
+	}
+	s.touch()
+}
+
+// expire is called when the session exceeds Server.MaxLifetime.
+func (s *session) expire() {
+	s.expired.Store(true)
+	if s.idle() {
+		s.close(ErrSessionExpired)
+	}
+}
+
 func (s *session) Close() error {
-	// return s.s.Close()
-	return nil
+	return s.close(ErrClosedConnection)
+}
+
+func (s *session) close(cause error) error {
+	s.cancel(cause)
+	return s.conn.Close()
+}
+
+// idle reports whether every request read from the client has been
+// responded to, and no request is partially read.
+func (s *session) idle() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.receiving && s.unread == 0 && len(s.responders) == 0
+}
+
+// sessionConn is the connection read by a session. It marks the session as
+// receiving a request as soon as any of the request is read, so the session
+// is not idle while a command is being read.
+type sessionConn struct {
+	net.Conn
+	s *session
+}
+
+func (c sessionConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.s.mu.Lock()
+		c.s.receiving = true
+		c.s.mu.Unlock()
+	}
+	return n, err
+}
+
+// push adds p for a request read from the connection to the responses
+// awaited from the caller of ReadCommand.
+func (s *session) push(p pending) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unread--
+	s.responders = append(s.responders, p)
+}
+
+// answered records that ReadCommand responded to a request read from the
+// connection. A request is counted in s.unread until answered, so the
+// session is not idle while the response is written.
+func (s *session) answered() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unread--
+}
+
+func (s *session) pop() (pending, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.responders) == 0 {
+		return pending{}, false
+	}
+	p := s.responders[0]
+	s.responders = s.responders[1:]
+	return p, true
+}
+
+// isConnError returns true if err was caused by the underlying connection,
+// rather than a malformed EPP message.
+func isConnError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, io.ErrClosedPipe) ||
+		errors.As(err, &netErr)
 }
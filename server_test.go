@@ -0,0 +1,823 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/domainr/epp2/ns"
+	"github.com/domainr/epp2/protocol"
+	"github.com/domainr/epp2/protocol/dataunit"
+	"github.com/domainr/epp2/schema/domain"
+	"github.com/domainr/epp2/schema/epp"
+)
+
+func TestServerHandle(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	ids := make(chan string, 1)
+	sessions := make(chan Session, 1)
+	s := &Server{
+		Name: "Test EPP Server",
+		Handler: func(sess Session) error {
+			sessions <- sess
+			for {
+				cmd, err := sess.ReadCommand()
+				if err != nil {
+					return err
+				}
+				if _, ok := cmd.Action.(*epp.Poll); ok {
+					ids <- cmd.ClientTransactionID
+				}
+				err = sess.WriteResponse(testResponse(cmd, epp.Success))
+				if err != nil {
+					return err
+				}
+			}
+		},
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Handle(serverConn)
+	}()
+
+	c, err := Connect(clientConn)
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	ctx := context.Background()
+	greeting, err := c.Hello(ctx)
+	if err != nil {
+		t.Fatalf("Hello(): err == %v", err)
+	}
+	if greeting.ServerName != s.Name {
+		t.Errorf("Hello(): ServerName == %q, expected %q", greeting.ServerName, s.Name)
+	}
+	err = c.Login(ctx, "user", "password", nil)
+	if err != nil {
+		t.Fatalf("Login(): err == %v", err)
+	}
+
+	body, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}, ClientTransactionID: "abc"})
+	if err != nil {
+		t.Fatalf("ExchangeEPP(): err == %v", err)
+	}
+	if id := <-ids; id != "abc" {
+		t.Errorf("server received clTRID %q, expected %q", id, "abc")
+	}
+	res, ok := body.(*epp.Response)
+	if !ok {
+		t.Fatalf("ExchangeEPP(): got %T, expected *epp.Response", body)
+	}
+	if res.TransactionID.Client != "abc" {
+		t.Errorf("ExchangeEPP(): clTRID == %q, expected %q", res.TransactionID.Client, "abc")
+	}
+
+	sess := <-sessions
+	c.Close()
+	<-sess.Context().Done()
+	if err := <-errs; err == nil {
+		t.Error("Handle(): err == nil, expected connection error")
+	}
+}
+
+func TestServerGreetingName(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverName string
+		want       string
+	}{
+		{"", "", DefaultServerName},
+		{"Server", "", "Server"},
+		{"Server", "Config", "Config"},
+	}
+	for _, tt := range tests {
+		s := &Server{Name: tt.name, Config: Config{ServerName: tt.serverName}}
+		body, err := s.greeting()
+		if err != nil {
+			t.Fatalf("greeting(): err == %v", err)
+		}
+		if got := body.(*epp.Greeting).ServerName; got != tt.want {
+			t.Errorf("greeting() with Name %q, ServerName %q: ServerName == %q, expected %q", tt.name, tt.serverName, got, tt.want)
+		}
+	}
+}
+
+func TestSessionWriteResponseNoCommand(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	s := &Server{
+		Handler: func(sess Session) error {
+			return sess.WriteResponse(&epp.Response{})
+		},
+	}
+	go func() {
+		// Read the greeting.
+		buf := make([]byte, 4096)
+		for {
+			if _, err := clientConn.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+	err := s.Handle(serverConn)
+	if err != ErrNoCommand {
+		t.Errorf("Handle(): err == %v, expected %v", err, ErrNoCommand)
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := make(chan struct{})
+	release := make(chan struct{})
+	s := &Server{
+		Handler: func(sess Session) error {
+			for {
+				cmd, err := sess.ReadCommand()
+				if err != nil {
+					return err
+				}
+				if _, ok := cmd.Action.(*epp.Login); !ok {
+					received <- struct{}{}
+					<-release
+				}
+				err = sess.WriteResponse(testResponse(cmd, epp.Success))
+				if err != nil {
+					return err
+				}
+			}
+		},
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.Serve(l)
+	}()
+
+	c, err := Dial("tcp", l.Addr().String(), WithPipeline(2))
+	if err != nil {
+		t.Fatalf("Dial(): err == %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	err = c.Login(ctx, "user", "password", nil)
+	if err != nil {
+		t.Fatalf("Login(): err == %v", err)
+	}
+
+	// Start a command, then shut down while it is in flight.
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		errs <- err
+	}()
+	<-received
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- s.Shutdown(ctx)
+	}()
+	if err := <-serveErr; err != ErrServerClosed {
+		t.Errorf("Serve(): err == %v, expected %v", err, ErrServerClosed)
+	}
+
+	// A new command during shutdown receives a 2500 response.
+	res := make(chan epp.Body, 1)
+	go func() {
+		body, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		res <- body
+		errs <- err
+	}()
+	for !testUnread(s) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("ExchangeEPP(): err == %v", err)
+		}
+	}
+	body := <-res
+	if r, ok := body.(*epp.Response); !ok || len(r.Results) == 0 || r.Results[0].Code != epp.ErrCommandFailedClosing {
+		t.Errorf("ExchangeEPP(): got %#v, expected result code %04d", body, epp.ErrCommandFailedClosing)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown(): err == %v", err)
+	}
+	if err := s.Serve(l); err != ErrServerClosed {
+		t.Errorf("Serve(): err == %v, expected %v", err, ErrServerClosed)
+	}
+}
+
+// testUnread reports whether any session on s has an unread request.
+func testUnread(s *Server) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sess := range s.sessions {
+		sess.mu.Lock()
+		n := sess.unread
+		sess.mu.Unlock()
+		if n > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServerShutdownReading(t *testing.T) {
+	s := &Server{
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	go s.Handle(serverConn)
+	if _, err := dataunit.Read(clientConn); err != nil {
+		t.Fatalf("dataunit.Read(): err == %v", err)
+	}
+
+	// Send part of a command, then shut down before the rest is sent.
+	cmd := []byte(`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command><poll op="req"/><clTRID>ABC-12345</clTRID></command></epp>`)
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(cmd)+4))
+	data = append(data, cmd...)
+	if _, err := clientConn.Write(data[:20]); err != nil {
+		t.Fatalf("Write(): err == %v", err)
+	}
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		shutdown <- s.Shutdown(ctx)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown() returned while reading a command: err == %v", err)
+	default:
+	}
+
+	// The command receives a response before the session is closed.
+	if _, err := clientConn.Write(data[20:]); err != nil {
+		t.Fatalf("Write(): err == %v", err)
+	}
+	res, err := dataunit.Read(clientConn)
+	if err != nil {
+		t.Fatalf("dataunit.Read(): err == %v", err)
+	}
+	if !bytes.Contains(res, []byte(`code="2500"`)) {
+		t.Errorf("response == %s, expected code 2500", res)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown(): err == %v", err)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	sessions := make(chan Session, 1)
+	s := &Server{
+		Handler: func(sess Session) error {
+			sessions <- sess
+			_, err := sess.ReadCommand()
+			return err
+		},
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Handle(serverConn)
+	}()
+
+	c, err := Connect(clientConn)
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+	sess := <-sessions
+
+	err = s.Close()
+	if err != nil {
+		t.Errorf("Close(): err == %v", err)
+	}
+	<-sess.Context().Done()
+	if err := <-errs; err != ErrServerClosed {
+		t.Errorf("Handle(): err == %v, expected %v", err, ErrServerClosed)
+	}
+	if err := s.Handle(serverConn); err != ErrServerClosed {
+		t.Errorf("Handle(): err == %v, expected %v", err, ErrServerClosed)
+	}
+}
+
+func TestServeCommands(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	mux := &ServeMux{}
+	mux.HandleFunc("login", "", func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+		return &epp.Response{Results: []epp.Result{{Code: epp.Success}}}, nil
+	})
+	mux.HandleFunc("poll", "", func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+		return &epp.Response{Results: []epp.Result{{Code: epp.SuccessNoMessages}}}, nil
+	})
+	s := &Server{Handler: ServeCommands(mux)}
+	go s.Handle(serverConn)
+
+	c, err := Connect(clientConn)
+	if err != nil {
+		t.Fatalf("Connect(): err == %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	err = c.Login(ctx, "user", "password", nil)
+	if err != nil {
+		t.Fatalf("Login(): err == %v", err)
+	}
+
+	tests := []struct {
+		action epp.Action
+		want   epp.ResultCode
+	}{
+		{&epp.Poll{}, epp.SuccessNoMessages},
+		{&epp.Info{}, epp.ErrUnimplementedCommand},
+	}
+	for _, tt := range tests {
+		body, err := c.ExchangeEPP(ctx, &epp.Command{Action: tt.action, ClientTransactionID: "abc"})
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+		res := body.(*epp.Response)
+		if res.Results[0].Code != tt.want {
+			t.Errorf("ExchangeEPP(): result code %04d, expected %04d", res.Results[0].Code, tt.want)
+		}
+		if res.TransactionID.Client != "abc" || res.TransactionID.Server == "" {
+			t.Errorf("ExchangeEPP(): trID == %+v", res.TransactionID)
+		}
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	s := &Server{
+		MaxLoginAttempts: 2,
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			switch a := cmd.Action.(type) {
+			case *epp.Login:
+				if a.Password != "password" {
+					return nil, epp.ErrAuthentication
+				}
+			case *epp.Info:
+				return testResponse(cmd, epp.ErrCommandFailedClosing), nil
+			case *epp.Renew:
+				return nil, context.Canceled
+			}
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	login := func(password string) *epp.Login {
+		return &epp.Login{
+			ClientID: "user",
+			Password: password,
+			Options:  epp.Options{Version: epp.Version},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		actions []epp.Action
+		want    []epp.ResultCode
+		closed  bool
+	}{
+		{
+			`login required`,
+			[]epp.Action{&epp.Poll{}, &epp.Logout{}, login("password"), &epp.Poll{}},
+			[]epp.ResultCode{epp.ErrCommandUse, epp.ErrCommandUse, epp.Success, epp.Success},
+			false,
+		},
+		{
+			`single login`,
+			[]epp.Action{login("password"), login("password")},
+			[]epp.ResultCode{epp.Success, epp.ErrCommandUse},
+			false,
+		},
+		{
+			`unsupported version`,
+			[]epp.Action{&epp.Login{ClientID: "user", Password: "password", Options: epp.Options{Version: "2.0"}}},
+			[]epp.ResultCode{epp.ErrUnimplementedVersion},
+			false,
+		},
+		{
+			`logout`,
+			[]epp.Action{login("password"), &epp.Logout{}},
+			[]epp.ResultCode{epp.Success, epp.SuccessEnd},
+			true,
+		},
+		{
+			`fatal response`,
+			[]epp.Action{login("password"), &epp.Info{}},
+			[]epp.ResultCode{epp.Success, epp.ErrCommandFailedClosing},
+			true,
+		},
+		{
+			`canceled handler`,
+			[]epp.Action{login("password"), &epp.Renew{}},
+			[]epp.ResultCode{epp.Success, epp.ErrCommandFailedClosing},
+			true,
+		},
+		{
+			`max login attempts`,
+			[]epp.Action{login("wrong"), login("wrong")},
+			[]epp.ResultCode{epp.ErrAuthentication, epp.ErrAuthenticationClosing},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testSessionClient(t, s)
+			ctx := context.Background()
+			for i, a := range tt.actions {
+				body, err := c.ExchangeEPP(ctx, &epp.Command{Action: a})
+				if err != nil {
+					t.Fatalf("ExchangeEPP(%d): err == %v", i, err)
+				}
+				if code := body.(*epp.Response).Results[0].Code; code != tt.want[i] {
+					t.Errorf("ExchangeEPP(%d): result code %04d, expected %04d", i, code, tt.want[i])
+				}
+			}
+			_, err := c.ExchangeEPP(ctx, &epp.Hello{})
+			if closed := err != nil; closed != tt.closed {
+				t.Errorf("session closed == %t, expected %t (err == %v)", closed, tt.closed, err)
+			}
+		})
+	}
+}
+
+func TestLoginConfig(t *testing.T) {
+	configs := make(chan *Config, 2)
+	s := &Server{
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			configs <- LoginConfig(ctx)
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	c := testSessionClient(t, s)
+	ctx := context.Background()
+	login := &epp.Login{
+		ClientID: "user",
+		Options:  epp.Options{Version: epp.Version},
+		Services: epp.Services{Objects: []string{ns.Domain}},
+	}
+	for _, a := range []epp.Action{login, &epp.Poll{}} {
+		if _, err := c.ExchangeEPP(ctx, &epp.Command{Action: a}); err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+	}
+	if cfg := <-configs; cfg != nil {
+		t.Errorf("LoginConfig() during <login> == %v, expected nil", cfg)
+	}
+	cfg := <-configs
+	if cfg == nil {
+		t.Fatal("LoginConfig() after <login> == nil")
+	}
+	if want := []string{ns.Domain}; !slices.Equal(cfg.Objects, want) {
+		t.Errorf("LoginConfig().Objects == %v, expected %v", cfg.Objects, want)
+	}
+}
+
+type testExtension struct {
+	XMLName struct{} `xml:"urn:example:ext-1.0 ext"`
+}
+
+func (testExtension) EPPExtension() {}
+
+func TestSessionPermits(t *testing.T) {
+	check := &epp.Check{Check: &domain.Check{Names: []string{"example.com"}}}
+	tests := []struct {
+		name   string
+		server Config
+		login  *Config
+		cmd    *epp.Command
+		want   error
+	}{
+		{
+			`not logged in`,
+			Config{},
+			nil,
+			&epp.Command{Action: check},
+			nil,
+		},
+		{
+			`negotiated object`,
+			Config{},
+			&Config{Objects: []string{ns.Domain}},
+			&epp.Command{Action: check},
+			nil,
+		},
+		{
+			`unnegotiated object`,
+			Config{},
+			&Config{Objects: []string{ns.Contact}},
+			&epp.Command{Action: check},
+			epp.ErrUnimplementedObject,
+		},
+		{
+			`no object`,
+			Config{},
+			&Config{},
+			&epp.Command{Action: &epp.Poll{}},
+			nil,
+		},
+		{
+			`negotiated extension`,
+			Config{},
+			&Config{Objects: []string{ns.Domain}, Extensions: []string{"urn:example:ext-1.0"}},
+			&epp.Command{Action: check, Extensions: epp.Extensions{testExtension{}}},
+			nil,
+		},
+		{
+			`unnegotiated extension`,
+			Config{},
+			&Config{Objects: []string{ns.Domain}},
+			&epp.Command{Action: check, Extensions: epp.Extensions{testExtension{}}},
+			epp.ErrUnimplementedExtension,
+		},
+		{
+			`unannounced extension`,
+			Config{UnannouncedExtensions: []string{"urn:example:ext-1.0"}},
+			&Config{Objects: []string{ns.Domain}},
+			&epp.Command{Action: check, Extensions: epp.Extensions{testExtension{}}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &session{server: &Server{Config: tt.server}, state: &sessionState{}}
+			if tt.login != nil {
+				s.state.login("user", tt.login)
+			}
+			if err := s.permits(tt.cmd); err != tt.want {
+				t.Errorf("permits() == %v, expected %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerMaxSessions(t *testing.T) {
+	s := &Server{
+		MaxSessions: 1,
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	ctx := context.Background()
+	login := &epp.Command{Action: &epp.Login{ClientID: "user", Options: epp.Options{Version: epp.Version}}}
+	for _, want := range []epp.ResultCode{epp.Success, epp.ErrSessionLimitExceeded} {
+		c := testSessionClient(t, s)
+		body, err := c.ExchangeEPP(ctx, login)
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+		if code := body.(*epp.Response).Results[0].Code; code != want {
+			t.Errorf("ExchangeEPP(): result code %04d, expected %04d", code, want)
+		}
+	}
+}
+
+// testSessionClient connects a low-level EPP client to a new session on s.
+func testSessionClient(t *testing.T, s *Server) protocol.Client {
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() { clientConn.Close() })
+	go s.Handle(serverConn)
+	c, _, err := protocol.Connect(context.Background(), clientConn)
+	if err != nil {
+		t.Fatalf("protocol.Connect(): err == %v", err)
+	}
+	return c
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	s := &Server{
+		IdleTimeout: 20 * time.Millisecond,
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	c := testSessionClient(t, s)
+	ctx := context.Background()
+
+	// Activity resets the idle timer.
+	for i := 0; i < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+		_, err := c.ExchangeEPP(ctx, &epp.Hello{})
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	_, err := c.ExchangeEPP(ctx, &epp.Hello{})
+	if err == nil {
+		t.Error("ExchangeEPP(): expected error after idle timeout")
+	}
+}
+
+func TestServerWriteTimeout(t *testing.T) {
+	s := &Server{
+		WriteTimeout: 20 * time.Millisecond,
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	done := make(chan struct{})
+	go func() {
+		s.Handle(serverConn)
+		close(done)
+	}()
+	if _, err := dataunit.Read(clientConn); err != nil {
+		t.Fatalf("dataunit.Read(): err == %v", err)
+	}
+
+	// Send a <hello>, but never read the response.
+	start := time.Now()
+	err := dataunit.Write(clientConn, []byte(`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><hello/></epp>`))
+	if err != nil {
+		t.Fatalf("dataunit.Write(): err == %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("session not closed after WriteTimeout")
+	}
+	if d := time.Since(start); d < s.WriteTimeout {
+		t.Errorf("session closed after %v, expected at least %v", d, s.WriteTimeout)
+	}
+}
+
+func TestServerMaxLifetime(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	s := &Server{
+		MaxLifetime: 20 * time.Millisecond,
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			if _, ok := cmd.Action.(*epp.Poll); ok {
+				received <- struct{}{}
+				<-release
+			}
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	c := testSessionClient(t, s)
+	ctx := context.Background()
+	login := &epp.Command{Action: &epp.Login{ClientID: "user", Options: epp.Options{Version: epp.Version}}}
+	_, err := c.ExchangeEPP(ctx, login)
+	if err != nil {
+		t.Fatalf("ExchangeEPP(): err == %v", err)
+	}
+
+	// A command in flight when the session expires completes normally.
+	errs := make(chan error, 1)
+	go func() {
+		_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+		errs <- err
+	}()
+	<-received
+	time.Sleep(30 * time.Millisecond)
+
+	// A new command after the session expires receives 2500.
+	res := make(chan epp.Body, 1)
+	go func() {
+		body, _ := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Info{}})
+		res <- body
+	}()
+	for !testUnread(s) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	if err := <-errs; err != nil {
+		t.Errorf("ExchangeEPP(): err == %v", err)
+	}
+	body := <-res
+	if r, ok := body.(*epp.Response); !ok || r.Results[0].Code != epp.ErrCommandFailedClosing {
+		t.Errorf("ExchangeEPP(): got %#v, expected result code %04d", body, epp.ErrCommandFailedClosing)
+	}
+}
+
+func TestServerMaxClientSessions(t *testing.T) {
+	s := &Server{
+		MaxClientSessions: 1,
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	ctx := context.Background()
+	login := func(clientID string) *epp.Command {
+		return &epp.Command{Action: &epp.Login{ClientID: clientID, Options: epp.Options{Version: epp.Version}}}
+	}
+	tests := []struct {
+		clientID string
+		want     epp.ResultCode
+	}{
+		{"alice", epp.Success},
+		{"bob", epp.Success},
+		{"alice", epp.ErrSessionLimitExceeded},
+	}
+	for _, tt := range tests {
+		c := testSessionClient(t, s)
+		body, err := c.ExchangeEPP(ctx, login(tt.clientID))
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+		if code := body.(*epp.Response).Results[0].Code; code != tt.want {
+			t.Errorf("login %s: result code %04d, expected %04d", tt.clientID, code, tt.want)
+		}
+	}
+}
+
+func TestServerMaxListenerSessions(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		MaxListenerSessions: 1,
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	go s.Serve(l)
+	defer s.Close()
+
+	ctx := context.Background()
+	login := &epp.Command{Action: &epp.Login{ClientID: "user", Options: epp.Options{Version: epp.Version}}}
+	for _, want := range []epp.ResultCode{epp.Success, epp.ErrSessionLimitExceeded} {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		c, _, err := protocol.Connect(ctx, conn)
+		if err != nil {
+			t.Fatalf("protocol.Connect(): err == %v", err)
+		}
+		body, err := c.ExchangeEPP(ctx, login)
+		if err != nil {
+			t.Fatalf("ExchangeEPP(): err == %v", err)
+		}
+		if code := body.(*epp.Response).Results[0].Code; code != want {
+			t.Errorf("ExchangeEPP(): result code %04d, expected %04d", code, want)
+		}
+	}
+}
+
+func TestServerMaxInFlight(t *testing.T) {
+	sessions := make(chan Session, 1)
+	s := &Server{
+		MaxInFlight: 2,
+		Handler: func(sess Session) error {
+			sessions <- sess
+			// Respond to <login>, then leave every other command in flight.
+			cmd, err := sess.ReadCommand()
+			if err != nil {
+				return err
+			}
+			err = sess.WriteResponse(testResponse(cmd, epp.Success))
+			if err != nil {
+				return err
+			}
+			for {
+				_, err := sess.ReadCommand()
+				if err != nil {
+					return err
+				}
+			}
+		},
+	}
+	c := testSessionClient(t, s)
+	sess := (<-sessions).(*session)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Login{ClientID: "user", Options: epp.Options{Version: epp.Version}}})
+	if err != nil {
+		t.Fatalf("ExchangeEPP(): err == %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		go c.ExchangeEPP(ctx, &epp.Command{Action: &epp.Poll{}})
+	}
+
+	// The session stops reading after 2 commands are in flight.
+	inFlight := func() int {
+		sess.mu.Lock()
+		defer sess.mu.Unlock()
+		return sess.unread + len(sess.responders)
+	}
+	for inFlight() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n := inFlight(); n != 2 {
+		t.Errorf("session has %d commands in flight, expected 2", n)
+	}
+}
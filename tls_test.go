@@ -0,0 +1,132 @@
+package epp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/domainr/epp2/schema/epp"
+)
+
+func TestServerCertificateClientIDs(t *testing.T) {
+	serverCert, _, _ := testCertificate(t, "server")
+	clientCert, certFile, keyFile := testCertificate(t, "registrar")
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert.Leaf)
+
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		ClientCAs:    pool,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := CertificateFingerprint(clientCert.Leaf)
+	s := &Server{
+		CertificateClientIDs: func(cert *x509.Certificate) []string {
+			if CertificateFingerprint(cert) == fingerprint {
+				return []string{cert.Subject.CommonName}
+			}
+			return nil
+		},
+		Handler: ServeCommands(HandlerFunc(func(ctx context.Context, cmd *epp.Command) (*epp.Response, error) {
+			return testResponse(cmd, epp.Success), nil
+		})),
+	}
+	go s.Serve(l)
+	defer s.Close()
+
+	cfg, err := LoadClientCertificate(&tls.Config{InsecureSkipVerify: true}, certFile, keyFile)
+	if err != nil {
+		t.Fatalf("LoadClientCertificate(): err == %v", err)
+	}
+	tests := []struct {
+		name     string
+		cfg      *tls.Config
+		clientID string
+		wantErr  bool
+	}{
+		{`matching client ID`, cfg, "registrar", false},
+		{`mismatched client ID`, cfg, "other", true},
+		{`no certificate`, &tls.Config{InsecureSkipVerify: true}, "registrar", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Dial("tcp", l.Addr().String(), WithTLS(tt.cfg))
+			if err != nil {
+				t.Fatalf("Dial(): err == %v", err)
+			}
+			defer c.Close()
+			err = c.Login(context.Background(), tt.clientID, "password", nil)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Login(): err == %v", err)
+				}
+				return
+			}
+			r, ok := err.(*epp.Result)
+			if !ok || r.Code != epp.ErrAuthentication {
+				t.Errorf("Login(): err == %v, expected result code %04d", err, epp.ErrAuthentication)
+			}
+		})
+	}
+}
+
+// testCertificate generates a self-signed certificate for name, writing the
+// PEM-encoded certificate and key to files in a temporary directory.
+func testCertificate(t *testing.T, name string) (cert tls.Certificate, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err = tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert.Leaf, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, certFile, keyFile
+}